@@ -1,12 +1,48 @@
-//go:build amd64
+//go:build amd64 && !purego
 
 package rapidhash
 
 import "unsafe"
 
+// useMULX selects the BMI2 kernel, which accumBlocks jumps to when it is
+// set. Micro and Nano always use MULQ: their loops have fewer lanes, and
+// MULX made them slower.
+var useMULX = hasBMI2()
+
+// hasBMI2 reports whether the CPU supports the BMI2 instructions (MULX).
+func hasBMI2() bool {
+	if maxID, _, _, _ := cpuid(0, 0); maxID < 7 {
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+
+	return ebx&(1<<8) != 0
+}
+
+// cpuid executes the CPUID instruction with the given leaf and subleaf.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
 // accumBlocks processes multiple 112-byte blocks using optimized assembly.
 // Returns the new pointer position, remaining length, and updated accumulators.
 //
 //go:noescape
-func accumBlocks(p unsafe.Pointer, length int, seed, see1, see2, see3, see4, see5, see6 uint64) (
+func accumBlocks(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, see5, see6 uint64) (
+	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4, nsee5, nsee6 uint64)
+
+// accumBlocksMicro processes multiple 80-byte Micro blocks using optimized
+// assembly.
+//
+//go:noescape
+func accumBlocksMicro(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4 uint64) (
+	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4 uint64)
+
+// accumBlocksNano processes multiple 48-byte Nano blocks using optimized
+// assembly.
+//
+//go:noescape
+func accumBlocksNano(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2 uint64) (
+	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2 uint64)
+
+//go:noescape
+func accumBlocksMULX(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, see5, see6 uint64) (
 	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4, nsee5, nsee6 uint64)
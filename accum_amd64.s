@@ -1,35 +1,34 @@
-//go:build amd64
+//go:build amd64 && !purego
 
 #include "textflag.h"
 
-// Secret constants (must match rapidhash.go)
-#define SECRET0 $0x2d358dccaa6c78a5
-#define SECRET1 $0x8bb84b93962eacc9
-#define SECRET2 $0x4b33a62ed433d4a3
-#define SECRET3 $0x4d5a2da51de1aa47
-#define SECRET4 $0xa0761d6478bd642f
-#define SECRET5 $0xe7037ed1a0b428db
-#define SECRET6 $0x90ed1765281c388c
-
-// func accumBlocks(p unsafe.Pointer, length int, seed, see1, see2, see3, see4, see5, see6 uint64) (
+// func accumBlocks(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, see5, see6 uint64) (
 //     newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4, nsee5, nsee6 uint64)
 //
 // Processes multiple 112-byte blocks with loop unrolling (2 blocks = 224 bytes per iteration).
-// This version keeps all accumulators in registers throughout the loop.
+// This version keeps all accumulators in registers throughout the loop; the
+// secrets are read from memory through BX, so R14 holds the multiplier.
 //
-TEXT ·accumBlocks(SB), NOSPLIT, $0-144
+TEXT ·accumBlocks(SB), NOSPLIT, $0-152
+    // Hand over to the MULX kernel when the CPU has BMI2
+    CMPB ·useMULX(SB), $0
+    JEQ mulq
+    JMP ·accumBlocksMULX(SB)
+
+mulq:
     // Load inputs
     MOVQ p+0(FP), SI          // SI = data pointer
     MOVQ length+8(FP), CX     // CX = remaining length
+    MOVQ secrets+16(FP), BX   // BX = secrets pointer
     
     // Load accumulators into callee-saved registers
-    MOVQ seed+16(FP), DI
-    MOVQ see1+24(FP), R8
-    MOVQ see2+32(FP), R9
-    MOVQ see3+40(FP), R10
-    MOVQ see4+48(FP), R11
-    MOVQ see5+56(FP), R12
-    MOVQ see6+64(FP), R13
+    MOVQ seed+24(FP), DI
+    MOVQ see1+32(FP), R8
+    MOVQ see2+40(FP), R9
+    MOVQ see3+48(FP), R10
+    MOVQ see4+56(FP), R11
+    MOVQ see5+64(FP), R12
+    MOVQ see6+72(FP), R13
 
     // Check if we have at least 224 bytes for unrolled loop
     CMPQ CX, $224
@@ -38,142 +37,128 @@ TEXT ·accumBlocks(SB), NOSPLIT, $0-144
 unrolled_loop:    
     // mix 0: seed = mix(p[0]^secret0, p[8]^seed)
     MOVQ 0(SI), AX
-    MOVQ 8(SI), BX
-    MOVQ SECRET0, R14
-    XORQ R14, AX
-    XORQ DI, BX
-    MULQ BX
+    MOVQ 8(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, DI
 
     // mix 1: see1 = mix(p[16]^secret1, p[24]^see1)
     MOVQ 16(SI), AX
-    MOVQ 24(SI), BX
-    MOVQ SECRET1, R14
-    XORQ R14, AX
-    XORQ R8, BX
-    MULQ BX
+    MOVQ 24(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R8
 
     // mix 2: see2 = mix(p[32]^secret2, p[40]^see2)
     MOVQ 32(SI), AX
-    MOVQ 40(SI), BX
-    MOVQ SECRET2, R14
-    XORQ R14, AX
-    XORQ R9, BX
-    MULQ BX
+    MOVQ 40(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R9
 
     // mix 3: see3 = mix(p[48]^secret3, p[56]^see3)
     MOVQ 48(SI), AX
-    MOVQ 56(SI), BX
-    MOVQ SECRET3, R14
-    XORQ R14, AX
-    XORQ R10, BX
-    MULQ BX
+    MOVQ 56(SI), R14
+    XORQ 24(BX), AX
+    XORQ R10, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R10
 
     // mix 4: see4 = mix(p[64]^secret4, p[72]^see4)
     MOVQ 64(SI), AX
-    MOVQ 72(SI), BX
-    MOVQ SECRET4, R14
-    XORQ R14, AX
-    XORQ R11, BX
-    MULQ BX
+    MOVQ 72(SI), R14
+    XORQ 32(BX), AX
+    XORQ R11, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R11
 
     // mix 5: see5 = mix(p[80]^secret5, p[88]^see5)
     MOVQ 80(SI), AX
-    MOVQ 88(SI), BX
-    MOVQ SECRET5, R14
-    XORQ R14, AX
-    XORQ R12, BX
-    MULQ BX
+    MOVQ 88(SI), R14
+    XORQ 40(BX), AX
+    XORQ R12, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R12
 
     // mix 6: see6 = mix(p[96]^secret6, p[104]^see6)
     MOVQ 96(SI), AX
-    MOVQ 104(SI), BX
-    MOVQ SECRET6, R14
-    XORQ R14, AX
-    XORQ R13, BX
-    MULQ BX
+    MOVQ 104(SI), R14
+    XORQ 48(BX), AX
+    XORQ R13, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R13
 
     
     // mix 0: seed = mix(p[112]^secret0, p[120]^seed)
     MOVQ 112(SI), AX
-    MOVQ 120(SI), BX
-    MOVQ SECRET0, R14
-    XORQ R14, AX
-    XORQ DI, BX
-    MULQ BX
+    MOVQ 120(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, DI
 
     // mix 1: see1 = mix(p[128]^secret1, p[136]^see1)
     MOVQ 128(SI), AX
-    MOVQ 136(SI), BX
-    MOVQ SECRET1, R14
-    XORQ R14, AX
-    XORQ R8, BX
-    MULQ BX
+    MOVQ 136(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R8
 
     // mix 2: see2 = mix(p[144]^secret2, p[152]^see2)
     MOVQ 144(SI), AX
-    MOVQ 152(SI), BX
-    MOVQ SECRET2, R14
-    XORQ R14, AX
-    XORQ R9, BX
-    MULQ BX
+    MOVQ 152(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R9
 
     // mix 3: see3 = mix(p[160]^secret3, p[168]^see3)
     MOVQ 160(SI), AX
-    MOVQ 168(SI), BX
-    MOVQ SECRET3, R14
-    XORQ R14, AX
-    XORQ R10, BX
-    MULQ BX
+    MOVQ 168(SI), R14
+    XORQ 24(BX), AX
+    XORQ R10, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R10
 
     // mix 4: see4 = mix(p[176]^secret4, p[184]^see4)
     MOVQ 176(SI), AX
-    MOVQ 184(SI), BX
-    MOVQ SECRET4, R14
-    XORQ R14, AX
-    XORQ R11, BX
-    MULQ BX
+    MOVQ 184(SI), R14
+    XORQ 32(BX), AX
+    XORQ R11, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R11
 
     // mix 5: see5 = mix(p[192]^secret5, p[200]^see5)
     MOVQ 192(SI), AX
-    MOVQ 200(SI), BX
-    MOVQ SECRET5, R14
-    XORQ R14, AX
-    XORQ R12, BX
-    MULQ BX
+    MOVQ 200(SI), R14
+    XORQ 40(BX), AX
+    XORQ R12, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R12
 
     // mix 6: see6 = mix(p[208]^secret6, p[216]^see6)
     MOVQ 208(SI), AX
-    MOVQ 216(SI), BX
-    MOVQ SECRET6, R14
-    XORQ R14, AX
-    XORQ R13, BX
-    MULQ BX
+    MOVQ 216(SI), R14
+    XORQ 48(BX), AX
+    XORQ R13, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R13
 
@@ -192,71 +177,64 @@ single_block_loop:
 
     // mix 0
     MOVQ 0(SI), AX
-    MOVQ 8(SI), BX
-    MOVQ SECRET0, R14
-    XORQ R14, AX
-    XORQ DI, BX
-    MULQ BX
+    MOVQ 8(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, DI
 
     // mix 1
     MOVQ 16(SI), AX
-    MOVQ 24(SI), BX
-    MOVQ SECRET1, R14
-    XORQ R14, AX
-    XORQ R8, BX
-    MULQ BX
+    MOVQ 24(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R8
 
     // mix 2
     MOVQ 32(SI), AX
-    MOVQ 40(SI), BX
-    MOVQ SECRET2, R14
-    XORQ R14, AX
-    XORQ R9, BX
-    MULQ BX
+    MOVQ 40(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R9
 
     // mix 3
     MOVQ 48(SI), AX
-    MOVQ 56(SI), BX
-    MOVQ SECRET3, R14
-    XORQ R14, AX
-    XORQ R10, BX
-    MULQ BX
+    MOVQ 56(SI), R14
+    XORQ 24(BX), AX
+    XORQ R10, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R10
 
     // mix 4
     MOVQ 64(SI), AX
-    MOVQ 72(SI), BX
-    MOVQ SECRET4, R14
-    XORQ R14, AX
-    XORQ R11, BX
-    MULQ BX
+    MOVQ 72(SI), R14
+    XORQ 32(BX), AX
+    XORQ R11, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R11
 
     // mix 5
     MOVQ 80(SI), AX
-    MOVQ 88(SI), BX
-    MOVQ SECRET5, R14
-    XORQ R14, AX
-    XORQ R12, BX
-    MULQ BX
+    MOVQ 88(SI), R14
+    XORQ 40(BX), AX
+    XORQ R12, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R12
 
     // mix 6
     MOVQ 96(SI), AX
-    MOVQ 104(SI), BX
-    MOVQ SECRET6, R14
-    XORQ R14, AX
-    XORQ R13, BX
-    MULQ BX
+    MOVQ 104(SI), R14
+    XORQ 48(BX), AX
+    XORQ R13, R14
+    MULQ R14
     XORQ DX, AX
     MOVQ AX, R13
 
@@ -266,13 +244,566 @@ single_block_loop:
 
 done:
     // Store results
-    MOVQ SI, newP+72(FP)
-    MOVQ CX, remaining+80(FP)
-    MOVQ DI, nseed+88(FP)
-    MOVQ R8, nsee1+96(FP)
-    MOVQ R9, nsee2+104(FP)
-    MOVQ R10, nsee3+112(FP)
-    MOVQ R11, nsee4+120(FP)
-    MOVQ R12, nsee5+128(FP)
-    MOVQ R13, nsee6+136(FP)
+    MOVQ SI, newP+80(FP)
+    MOVQ CX, remaining+88(FP)
+    MOVQ DI, nseed+96(FP)
+    MOVQ R8, nsee1+104(FP)
+    MOVQ R9, nsee2+112(FP)
+    MOVQ R10, nsee3+120(FP)
+    MOVQ R11, nsee4+128(FP)
+    MOVQ R12, nsee5+136(FP)
+    MOVQ R13, nsee6+144(FP)
+    RET
+
+// func accumBlocksMicro(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4 uint64) (
+//     newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4 uint64)
+//
+// Processes multiple 80-byte Micro blocks with loop unrolling (2 blocks = 160 bytes
+// per iteration), with the same register layout as accumBlocks.
+//
+TEXT ·accumBlocksMicro(SB), NOSPLIT, $0-120
+    // Load inputs
+    MOVQ p+0(FP), SI          // SI = data pointer
+    MOVQ length+8(FP), CX     // CX = remaining length
+    MOVQ secrets+16(FP), BX   // BX = secrets pointer
+
+    // Load accumulators
+    MOVQ seed+24(FP), DI
+    MOVQ see1+32(FP), R8
+    MOVQ see2+40(FP), R9
+    MOVQ see3+48(FP), R10
+    MOVQ see4+56(FP), R11
+
+    // Check if we have at least 160 bytes for unrolled loop
+    CMPQ CX, $160
+    JLE single_block_loop
+
+unrolled_loop:
+    // mix 0: seed = mix(p[0]^secret0, p[8]^seed)
+    MOVQ 0(SI), AX
+    MOVQ 8(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, DI
+
+    // mix 1: see1 = mix(p[16]^secret1, p[24]^see1)
+    MOVQ 16(SI), AX
+    MOVQ 24(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R8
+
+    // mix 2: see2 = mix(p[32]^secret2, p[40]^see2)
+    MOVQ 32(SI), AX
+    MOVQ 40(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R9
+
+    // mix 3: see3 = mix(p[48]^secret3, p[56]^see3)
+    MOVQ 48(SI), AX
+    MOVQ 56(SI), R14
+    XORQ 24(BX), AX
+    XORQ R10, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R10
+
+    // mix 4: see4 = mix(p[64]^secret4, p[72]^see4)
+    MOVQ 64(SI), AX
+    MOVQ 72(SI), R14
+    XORQ 32(BX), AX
+    XORQ R11, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R11
+
+    // mix 0: seed = mix(p[80]^secret0, p[88]^seed)
+    MOVQ 80(SI), AX
+    MOVQ 88(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, DI
+
+    // mix 1: see1 = mix(p[96]^secret1, p[104]^see1)
+    MOVQ 96(SI), AX
+    MOVQ 104(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R8
+
+    // mix 2: see2 = mix(p[112]^secret2, p[120]^see2)
+    MOVQ 112(SI), AX
+    MOVQ 120(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R9
+
+    // mix 3: see3 = mix(p[128]^secret3, p[136]^see3)
+    MOVQ 128(SI), AX
+    MOVQ 136(SI), R14
+    XORQ 24(BX), AX
+    XORQ R10, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R10
+
+    // mix 4: see4 = mix(p[144]^secret4, p[152]^see4)
+    MOVQ 144(SI), AX
+    MOVQ 152(SI), R14
+    XORQ 32(BX), AX
+    XORQ R11, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R11
+
+    // Advance pointer and decrement length
+    ADDQ $160, SI
+    SUBQ $160, CX
+
+    // Continue if we have 161+ bytes remaining
+    CMPQ CX, $160
+    JG unrolled_loop
+
+single_block_loop:
+    // Process remaining 80-byte blocks one at a time
+    CMPQ CX, $80
+    JLE done
+
+    // mix 0
+    MOVQ 0(SI), AX
+    MOVQ 8(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, DI
+
+    // mix 1
+    MOVQ 16(SI), AX
+    MOVQ 24(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R8
+
+    // mix 2
+    MOVQ 32(SI), AX
+    MOVQ 40(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R9
+
+    // mix 3
+    MOVQ 48(SI), AX
+    MOVQ 56(SI), R14
+    XORQ 24(BX), AX
+    XORQ R10, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R10
+
+    // mix 4
+    MOVQ 64(SI), AX
+    MOVQ 72(SI), R14
+    XORQ 32(BX), AX
+    XORQ R11, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R11
+
+    ADDQ $80, SI
+    SUBQ $80, CX
+    JMP single_block_loop
+
+done:
+    // Store results
+    MOVQ SI, newP+64(FP)
+    MOVQ CX, remaining+72(FP)
+    MOVQ DI, nseed+80(FP)
+    MOVQ R8, nsee1+88(FP)
+    MOVQ R9, nsee2+96(FP)
+    MOVQ R10, nsee3+104(FP)
+    MOVQ R11, nsee4+112(FP)
+    RET
+
+// func accumBlocksNano(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2 uint64) (
+//     newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2 uint64)
+//
+// Processes multiple 48-byte Nano blocks with loop unrolling (2 blocks = 96 bytes
+// per iteration), with the same register layout as accumBlocks.
+//
+TEXT ·accumBlocksNano(SB), NOSPLIT, $0-88
+    // Load inputs
+    MOVQ p+0(FP), SI          // SI = data pointer
+    MOVQ length+8(FP), CX     // CX = remaining length
+    MOVQ secrets+16(FP), BX   // BX = secrets pointer
+
+    // Load accumulators
+    MOVQ seed+24(FP), DI
+    MOVQ see1+32(FP), R8
+    MOVQ see2+40(FP), R9
+
+    // Check if we have at least 96 bytes for unrolled loop
+    CMPQ CX, $96
+    JLE single_block_loop
+
+unrolled_loop:
+    // mix 0: seed = mix(p[0]^secret0, p[8]^seed)
+    MOVQ 0(SI), AX
+    MOVQ 8(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, DI
+
+    // mix 1: see1 = mix(p[16]^secret1, p[24]^see1)
+    MOVQ 16(SI), AX
+    MOVQ 24(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R8
+
+    // mix 2: see2 = mix(p[32]^secret2, p[40]^see2)
+    MOVQ 32(SI), AX
+    MOVQ 40(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R9
+
+    // mix 0: seed = mix(p[48]^secret0, p[56]^seed)
+    MOVQ 48(SI), AX
+    MOVQ 56(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, DI
+
+    // mix 1: see1 = mix(p[64]^secret1, p[72]^see1)
+    MOVQ 64(SI), AX
+    MOVQ 72(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R8
+
+    // mix 2: see2 = mix(p[80]^secret2, p[88]^see2)
+    MOVQ 80(SI), AX
+    MOVQ 88(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R9
+
+    // Advance pointer and decrement length
+    ADDQ $96, SI
+    SUBQ $96, CX
+
+    // Continue if we have 97+ bytes remaining
+    CMPQ CX, $96
+    JG unrolled_loop
+
+single_block_loop:
+    // Process remaining 48-byte blocks one at a time
+    CMPQ CX, $48
+    JLE done
+
+    // mix 0
+    MOVQ 0(SI), AX
+    MOVQ 8(SI), R14
+    XORQ 0(BX), AX
+    XORQ DI, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, DI
+
+    // mix 1
+    MOVQ 16(SI), AX
+    MOVQ 24(SI), R14
+    XORQ 8(BX), AX
+    XORQ R8, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R8
+
+    // mix 2
+    MOVQ 32(SI), AX
+    MOVQ 40(SI), R14
+    XORQ 16(BX), AX
+    XORQ R9, R14
+    MULQ R14
+    XORQ DX, AX
+    MOVQ AX, R9
+
+    ADDQ $48, SI
+    SUBQ $48, CX
+    JMP single_block_loop
+
+done:
+    // Store results
+    MOVQ SI, newP+48(FP)
+    MOVQ CX, remaining+56(FP)
+    MOVQ DI, nseed+64(FP)
+    MOVQ R8, nsee1+72(FP)
+    MOVQ R9, nsee2+80(FP)
+    RET
+
+// func accumBlocksMULX(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, see5, see6 uint64) (
+//     newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4, nsee5, nsee6 uint64)
+//
+// The BMI2 version of accumBlocks, selected at init when the CPU has it. MULX
+// multiplies DX into any two destination registers without touching flags,
+// so the high half lands directly in the lane register and RAX is free.
+//
+TEXT ·accumBlocksMULX(SB), NOSPLIT, $0-152
+    // Load inputs
+    MOVQ p+0(FP), SI          // SI = data pointer
+    MOVQ length+8(FP), CX     // CX = remaining length
+    MOVQ secrets+16(FP), BX   // BX = secrets pointer
+
+    // Load accumulators
+    MOVQ seed+24(FP), DI
+    MOVQ see1+32(FP), R8
+    MOVQ see2+40(FP), R9
+    MOVQ see3+48(FP), R10
+    MOVQ see4+56(FP), R11
+    MOVQ see5+64(FP), R12
+    MOVQ see6+72(FP), R13
+
+    // Check if we have at least 224 bytes for unrolled loop
+    CMPQ CX, $224
+    JLE single_block_loop
+
+unrolled_loop:
+    // mix 0: seed = mix(p[0]^secret0, p[8]^seed)
+    MOVQ 0(SI), AX
+    MOVQ 8(SI), DX
+    XORQ 0(BX), AX
+    XORQ DI, DX
+    MULXQ AX, R14, DI
+    XORQ R14, DI
+
+    // mix 1: see1 = mix(p[16]^secret1, p[24]^see1)
+    MOVQ 16(SI), AX
+    MOVQ 24(SI), DX
+    XORQ 8(BX), AX
+    XORQ R8, DX
+    MULXQ AX, R14, R8
+    XORQ R14, R8
+
+    // mix 2: see2 = mix(p[32]^secret2, p[40]^see2)
+    MOVQ 32(SI), AX
+    MOVQ 40(SI), DX
+    XORQ 16(BX), AX
+    XORQ R9, DX
+    MULXQ AX, R14, R9
+    XORQ R14, R9
+
+    // mix 3: see3 = mix(p[48]^secret3, p[56]^see3)
+    MOVQ 48(SI), AX
+    MOVQ 56(SI), DX
+    XORQ 24(BX), AX
+    XORQ R10, DX
+    MULXQ AX, R14, R10
+    XORQ R14, R10
+
+    // mix 4: see4 = mix(p[64]^secret4, p[72]^see4)
+    MOVQ 64(SI), AX
+    MOVQ 72(SI), DX
+    XORQ 32(BX), AX
+    XORQ R11, DX
+    MULXQ AX, R14, R11
+    XORQ R14, R11
+
+    // mix 5: see5 = mix(p[80]^secret5, p[88]^see5)
+    MOVQ 80(SI), AX
+    MOVQ 88(SI), DX
+    XORQ 40(BX), AX
+    XORQ R12, DX
+    MULXQ AX, R14, R12
+    XORQ R14, R12
+
+    // mix 6: see6 = mix(p[96]^secret6, p[104]^see6)
+    MOVQ 96(SI), AX
+    MOVQ 104(SI), DX
+    XORQ 48(BX), AX
+    XORQ R13, DX
+    MULXQ AX, R14, R13
+    XORQ R14, R13
+
+
+    // mix 0: seed = mix(p[112]^secret0, p[120]^seed)
+    MOVQ 112(SI), AX
+    MOVQ 120(SI), DX
+    XORQ 0(BX), AX
+    XORQ DI, DX
+    MULXQ AX, R14, DI
+    XORQ R14, DI
+
+    // mix 1: see1 = mix(p[128]^secret1, p[136]^see1)
+    MOVQ 128(SI), AX
+    MOVQ 136(SI), DX
+    XORQ 8(BX), AX
+    XORQ R8, DX
+    MULXQ AX, R14, R8
+    XORQ R14, R8
+
+    // mix 2: see2 = mix(p[144]^secret2, p[152]^see2)
+    MOVQ 144(SI), AX
+    MOVQ 152(SI), DX
+    XORQ 16(BX), AX
+    XORQ R9, DX
+    MULXQ AX, R14, R9
+    XORQ R14, R9
+
+    // mix 3: see3 = mix(p[160]^secret3, p[168]^see3)
+    MOVQ 160(SI), AX
+    MOVQ 168(SI), DX
+    XORQ 24(BX), AX
+    XORQ R10, DX
+    MULXQ AX, R14, R10
+    XORQ R14, R10
+
+    // mix 4: see4 = mix(p[176]^secret4, p[184]^see4)
+    MOVQ 176(SI), AX
+    MOVQ 184(SI), DX
+    XORQ 32(BX), AX
+    XORQ R11, DX
+    MULXQ AX, R14, R11
+    XORQ R14, R11
+
+    // mix 5: see5 = mix(p[192]^secret5, p[200]^see5)
+    MOVQ 192(SI), AX
+    MOVQ 200(SI), DX
+    XORQ 40(BX), AX
+    XORQ R12, DX
+    MULXQ AX, R14, R12
+    XORQ R14, R12
+
+    // mix 6: see6 = mix(p[208]^secret6, p[216]^see6)
+    MOVQ 208(SI), AX
+    MOVQ 216(SI), DX
+    XORQ 48(BX), AX
+    XORQ R13, DX
+    MULXQ AX, R14, R13
+    XORQ R14, R13
+
+    // Advance pointer and decrement length
+    ADDQ $224, SI
+    SUBQ $224, CX
+
+    // Continue if we have 225+ bytes remaining
+    CMPQ CX, $224
+    JG unrolled_loop
+
+single_block_loop:
+    // Process remaining 112-byte blocks one at a time
+    CMPQ CX, $112
+    JLE done
+
+    // mix 0
+    MOVQ 0(SI), AX
+    MOVQ 8(SI), DX
+    XORQ 0(BX), AX
+    XORQ DI, DX
+    MULXQ AX, R14, DI
+    XORQ R14, DI
+
+    // mix 1
+    MOVQ 16(SI), AX
+    MOVQ 24(SI), DX
+    XORQ 8(BX), AX
+    XORQ R8, DX
+    MULXQ AX, R14, R8
+    XORQ R14, R8
+
+    // mix 2
+    MOVQ 32(SI), AX
+    MOVQ 40(SI), DX
+    XORQ 16(BX), AX
+    XORQ R9, DX
+    MULXQ AX, R14, R9
+    XORQ R14, R9
+
+    // mix 3
+    MOVQ 48(SI), AX
+    MOVQ 56(SI), DX
+    XORQ 24(BX), AX
+    XORQ R10, DX
+    MULXQ AX, R14, R10
+    XORQ R14, R10
+
+    // mix 4
+    MOVQ 64(SI), AX
+    MOVQ 72(SI), DX
+    XORQ 32(BX), AX
+    XORQ R11, DX
+    MULXQ AX, R14, R11
+    XORQ R14, R11
+
+    // mix 5
+    MOVQ 80(SI), AX
+    MOVQ 88(SI), DX
+    XORQ 40(BX), AX
+    XORQ R12, DX
+    MULXQ AX, R14, R12
+    XORQ R14, R12
+
+    // mix 6
+    MOVQ 96(SI), AX
+    MOVQ 104(SI), DX
+    XORQ 48(BX), AX
+    XORQ R13, DX
+    MULXQ AX, R14, R13
+    XORQ R14, R13
+
+    ADDQ $112, SI
+    SUBQ $112, CX
+    JMP single_block_loop
+
+done:
+    // Store results
+    MOVQ SI, newP+80(FP)
+    MOVQ CX, remaining+88(FP)
+    MOVQ DI, nseed+96(FP)
+    MOVQ R8, nsee1+104(FP)
+    MOVQ R9, nsee2+112(FP)
+    MOVQ R10, nsee3+120(FP)
+    MOVQ R11, nsee4+128(FP)
+    MOVQ R12, nsee5+136(FP)
+    MOVQ R13, nsee6+144(FP)
+    RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+    MOVL eaxArg+0(FP), AX
+    MOVL ecxArg+4(FP), CX
+    CPUID
+    MOVL AX, eax+8(FP)
+    MOVL BX, ebx+12(FP)
+    MOVL CX, ecx+16(FP)
+    MOVL DX, edx+20(FP)
     RET
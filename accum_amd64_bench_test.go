@@ -0,0 +1,33 @@
+//go:build amd64 && !purego
+
+package rapidhash_test
+
+import (
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func BenchmarkKernelsAMD64(b *testing.B) {
+	data := make([]byte, 64<<10)
+
+	for _, k := range []struct {
+		name string
+		mulx bool
+	}{
+		{"MULQ", false},
+		{"MULX", true},
+	} {
+		b.Run(k.name, func(b *testing.B) {
+			if k.mulx && !rapidhash.HasBMI2() {
+				b.Skip("CPU does not support BMI2")
+			}
+			defer rapidhash.UseMULX(k.mulx)()
+
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				sink = rapidhash.Hash(data)
+			}
+		})
+	}
+}
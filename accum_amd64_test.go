@@ -0,0 +1,30 @@
+//go:build amd64 && !purego
+
+package rapidhash_test
+
+import (
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+// TestAccumBlocksKernelsAMD64 forces each amd64 kernel in turn, so both are
+// checked regardless of which one init selected.
+func TestAccumBlocksKernelsAMD64(t *testing.T) {
+	for _, k := range []struct {
+		name string
+		mulx bool
+	}{
+		{"MULQ", false},
+		{"MULX", true},
+	} {
+		t.Run(k.name, func(t *testing.T) {
+			if k.mulx && !rapidhash.HasBMI2() {
+				t.Skip("CPU does not support BMI2")
+			}
+			defer rapidhash.UseMULX(k.mulx)()
+
+			testAccumBlocks(t)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !amd64 || purego
+
+package rapidhash
+
+import "unsafe"
+
+// accumBlocks processes multiple 112-byte blocks.
+//
+// This is the generic fallback for platforms without an assembly kernel and
+// for purego builds.
+func accumBlocks(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, see5, see6 uint64) (
+	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4, nsee5, nsee6 uint64) {
+
+	return accumBlocksGeneric(p, length, secrets, seed, see1, see2, see3, see4, see5, see6)
+}
+
+// accumBlocksMicro processes multiple 80-byte Micro blocks.
+func accumBlocksMicro(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4 uint64) (
+	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2, nsee3, nsee4 uint64) {
+
+	return accumBlocksMicroGeneric(p, length, secrets, seed, see1, see2, see3, see4)
+}
+
+// accumBlocksNano processes multiple 48-byte Nano blocks.
+func accumBlocksNano(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2 uint64) (
+	newP unsafe.Pointer, remaining int, nseed, nsee1, nsee2 uint64) {
+
+	return accumBlocksNanoGeneric(p, length, secrets, seed, see1, see2)
+}
@@ -0,0 +1,56 @@
+package rapidhash_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+// TestAccumBlocksMatchesGeneric checks the block kernels selected for this
+// build (assembly where available) against the portable Go loops.
+func TestAccumBlocksMatchesGeneric(t *testing.T) {
+	testAccumBlocks(t)
+}
+
+func testAccumBlocks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, 4096)
+	rng.Read(data)
+
+	secrets := []rapidhash.Secrets{
+		rapidhash.MakeSecrets(0),
+		rapidhash.MakeSecrets(42),
+	}
+
+	sizes := []int{0, 1, 48, 49, 80, 81, 96, 97, 112, 113, 160, 161, 224, 225, 336, 337, 448, 449, 1000, 4096}
+	for i := 0; i < 20; i++ {
+		sizes = append(sizes, rng.Intn(len(data)+1))
+	}
+
+	kernels := []struct {
+		name            string
+		kernel, generic func([]byte, uint64, *rapidhash.Secrets) (int, [7]uint64)
+	}{
+		{"Default", rapidhash.AccumBlocks, rapidhash.AccumBlocksGeneric},
+		{"Micro", rapidhash.AccumBlocksMicro, rapidhash.AccumBlocksMicroGeneric},
+		{"Nano", rapidhash.AccumBlocksNano, rapidhash.AccumBlocksNanoGeneric},
+	}
+
+	for _, k := range kernels {
+		for _, s := range secrets {
+			s := s
+			for _, size := range sizes {
+				seed := rng.Uint64()
+
+				gotRem, gotLanes := k.kernel(data[:size], seed, &s)
+				wantRem, wantLanes := k.generic(data[:size], seed, &s)
+
+				if gotRem != wantRem || gotLanes != wantLanes {
+					t.Errorf("%s size=%d: kernel = (%d, %x), generic = (%d, %x)",
+						k.name, size, gotRem, gotLanes, wantRem, wantLanes)
+				}
+			}
+		}
+	}
+}
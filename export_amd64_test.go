@@ -0,0 +1,15 @@
+//go:build amd64 && !purego
+
+package rapidhash
+
+// HasBMI2 reports whether the MULX kernels can run on this CPU.
+var HasBMI2 = hasBMI2
+
+// UseMULX forces the MULX kernels on or off and returns a function that
+// restores the selection made at init.
+func UseMULX(on bool) (restore func()) {
+	old := useMULX
+	useMULX = on
+
+	return func() { useMULX = old }
+}
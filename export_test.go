@@ -0,0 +1,92 @@
+package rapidhash
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// AccumBlocks runs the block kernel selected for this build over data, with
+// every lane starting at seed, and returns the remaining length and lanes.
+func AccumBlocks(data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	return runAccum(accumBlocks, data, seed, secrets)
+}
+
+// AccumBlocksGeneric is AccumBlocks using the portable Go kernel.
+func AccumBlocksGeneric(data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	return runAccum(accumBlocksGeneric, data, seed, secrets)
+}
+
+// AccumBlocksMicro is AccumBlocks for the Micro kernel; lanes 5 and 6 are 0.
+func AccumBlocksMicro(data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	return runAccum(microAccum(accumBlocksMicro), data, seed, secrets)
+}
+
+// AccumBlocksMicroGeneric is AccumBlocksMicro using the portable Go kernel.
+func AccumBlocksMicroGeneric(data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	return runAccum(microAccum(accumBlocksMicroGeneric), data, seed, secrets)
+}
+
+// AccumBlocksNano is AccumBlocks for the Nano kernel; lanes 3 to 6 are 0.
+func AccumBlocksNano(data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	return runAccum(nanoAccum(accumBlocksNano), data, seed, secrets)
+}
+
+// AccumBlocksNanoGeneric is AccumBlocksNano using the portable Go kernel.
+func AccumBlocksNanoGeneric(data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	return runAccum(nanoAccum(accumBlocksNanoGeneric), data, seed, secrets)
+}
+
+type accumFunc func(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, see5, see6 uint64) (
+	unsafe.Pointer, int, uint64, uint64, uint64, uint64, uint64, uint64, uint64)
+
+func runAccum(f accumFunc, data []byte, seed uint64, secrets *Secrets) (int, [7]uint64) {
+	var l [7]uint64
+	p := unsafe.Pointer(unsafe.SliceData(data))
+	_, rem, l0, l1, l2, l3, l4, l5, l6 := f(p, len(data), secrets, seed, seed, seed, seed, seed, seed, seed)
+	l[0], l[1], l[2], l[3], l[4], l[5], l[6] = l0, l1, l2, l3, l4, l5, l6
+
+	return rem, l
+}
+
+func microAccum(f func(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4 uint64) (
+	unsafe.Pointer, int, uint64, uint64, uint64, uint64, uint64)) accumFunc {
+
+	return func(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, see3, see4, _, _ uint64) (
+		unsafe.Pointer, int, uint64, uint64, uint64, uint64, uint64, uint64, uint64) {
+
+		p, length, seed, see1, see2, see3, see4 = f(p, length, secrets, seed, see1, see2, see3, see4)
+
+		return p, length, seed, see1, see2, see3, see4, 0, 0
+	}
+}
+
+func nanoAccum(f func(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2 uint64) (
+	unsafe.Pointer, int, uint64, uint64, uint64)) accumFunc {
+
+	return func(p unsafe.Pointer, length int, secrets *Secrets, seed, see1, see2, _, _, _, _ uint64) (
+		unsafe.Pointer, int, uint64, uint64, uint64, uint64, uint64, uint64, uint64) {
+
+		p, length, seed, see1, see2 = f(p, length, secrets, seed, see1, see2)
+
+		return p, length, seed, see1, see2, 0, 0, 0, 0
+	}
+}
+
+// HashMapped exposes the memory-mapped path of HashOSFile.
+var HashMapped = hashMapped
+
+// AppendComparableStable returns the stable comparable encoding of v.
+func AppendComparableStable(v any) ([]byte, error) {
+	return appendComparable(nil, v, true)
+}
+
+// TypeName returns the name of t in the comparable encoding.
+func TypeName(t reflect.Type) string {
+	return string(typeName(nil, t))
+}
+
+// RawComparable reports whether HashComparable hashes values of type T as
+// their memory.
+func RawComparable[T comparable]() bool {
+	return encoderFor[T]().raw
+}
@@ -0,0 +1,24 @@
+//go:build !purego && !(mips || mips64 || ppc64 || s390x)
+
+package rapidhash
+
+import "unsafe"
+
+// nativeLE reports whether integers are laid out in memory in the
+// little-endian order that encodings use, so they can be copied as bytes.
+const nativeLE = true
+
+// u64 reads a little-endian uint64 using unsafe pointer arithmetic.
+// This eliminates bounds checking for maximum performance.
+//
+//go:nosplit
+func u64(p unsafe.Pointer) uint64 {
+	return *(*uint64)(p)
+}
+
+// u32 reads a little-endian uint32 using unsafe pointer arithmetic.
+//
+//go:nosplit
+func u32(p unsafe.Pointer) uint64 {
+	return uint64(*(*uint32)(p))
+}
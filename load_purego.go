@@ -0,0 +1,24 @@
+//go:build purego || mips || mips64 || ppc64 || s390x
+
+package rapidhash
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+// nativeLE is false, so encodings never copy integers from memory.
+const nativeLE = false
+
+// u64 reads a little-endian uint64 byte by byte, so the result does not
+// depend on the platform byte order or on unaligned-load support.
+//
+// This is selected by the purego build tag and on big-endian platforms.
+func u64(p unsafe.Pointer) uint64 {
+	return binary.LittleEndian.Uint64((*[8]byte)(p)[:])
+}
+
+// u32 reads a little-endian uint32 byte by byte.
+func u32(p unsafe.Pointer) uint64 {
+	return uint64(binary.LittleEndian.Uint32((*[4]byte)(p)[:]))
+}
@@ -40,21 +40,6 @@ func mum(a, b uint64) (uint64, uint64) {
 	return lo, hi
 }
 
-// u64 reads a little-endian uint64 using unsafe pointer arithmetic.
-// This eliminates bounds checking for maximum performance.
-//
-//go:nosplit
-func u64(p unsafe.Pointer) uint64 {
-	return *(*uint64)(p)
-}
-
-// u32 reads a little-endian uint32 using unsafe pointer arithmetic.
-//
-//go:nosplit
-func u32(p unsafe.Pointer) uint64 {
-	return uint64(*(*uint32)(p))
-}
-
 // add returns a pointer offset by n bytes.
 //
 //go:nosplit
@@ -79,7 +64,7 @@ func hashLargeBlock(p unsafe.Pointer, i int, seed uint64) (unsafe.Pointer, int,
 	see3, see4 := seed, seed
 	see5, see6 := seed, seed
 
-	p, i, seed, see1, see2, see3, see4, see5, see6 = accumBlocks(p, i, seed, see1, see2, see3, see4, see5, see6)
+	p, i, seed, see1, see2, see3, see4, see5, see6 = accumBlocks(p, i, &defaultSecrets, seed, see1, see2, see3, see4, see5, see6)
 
 	seed ^= see1
 	see2 ^= see3
@@ -354,13 +339,7 @@ func hashNanoWithMixedSeed(data []byte, p unsafe.Pointer, length int, seed uint6
 	if length > 48 {
 		see1, see2 := seed, seed
 
-		for i > 48 {
-			seed = mix(u64(p)^secret0, u64(add(p, 8))^seed)
-			see1 = mix(u64(add(p, 16))^secret1, u64(add(p, 24))^see1)
-			see2 = mix(u64(add(p, 32))^secret2, u64(add(p, 40))^see2)
-			p = add(p, 48)
-			i -= 48
-		}
+		p, i, seed, see1, see2 = accumBlocksNano(p, i, &defaultSecrets, seed, see1, see2)
 
 		seed ^= see1
 		seed ^= see2
@@ -486,15 +465,7 @@ func hashMicroWithMixedSeed(data []byte, p unsafe.Pointer, length int, seed uint
 		see1, see2 := seed, seed
 		see3, see4 := seed, seed
 
-		for i > 80 {
-			seed = mix(u64(p)^secret0, u64(add(p, 8))^seed)
-			see1 = mix(u64(add(p, 16))^secret1, u64(add(p, 24))^see1)
-			see2 = mix(u64(add(p, 32))^secret2, u64(add(p, 40))^see2)
-			see3 = mix(u64(add(p, 48))^secret3, u64(add(p, 56))^see3)
-			see4 = mix(u64(add(p, 64))^secret4, u64(add(p, 72))^see4)
-			p = add(p, 80)
-			i -= 80
-		}
+		p, i, seed, see1, see2, see3, see4 = accumBlocksMicro(p, i, &defaultSecrets, seed, see1, see2, see3, see4)
 
 		seed ^= see1
 		see2 ^= see3
@@ -0,0 +1,187 @@
+package rapidhash
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+var _ encoding.TextMarshaler = Uint128{}
+var _ encoding.TextUnmarshaler = (*Uint128)(nil)
+var _ fmt.Stringer = Uint128{}
+
+var errUint128Text = errors.New("rapidhash: invalid 128-bit hash text")
+
+// Uint128 is a 128-bit hash value.
+//
+// It is comparable, so it can be used directly as a map key, and its text
+// form is 32 lowercase hex digits, Hi first.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// String returns u as 32 lowercase hex digits.
+func (u Uint128) String() string {
+	return string(u.appendHex(make([]byte, 0, 32)))
+}
+
+// AppendText appends the hex form of u to b and returns the resulting slice.
+func (u Uint128) AppendText(b []byte) ([]byte, error) {
+	return u.appendHex(b), nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (u Uint128) MarshalText() ([]byte, error) {
+	return u.appendHex(make([]byte, 0, 32)), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts exactly 32
+// hex digits in either case.
+func (u *Uint128) UnmarshalText(text []byte) error {
+	if len(text) != 32 {
+		return errUint128Text
+	}
+
+	var v [2]uint64
+	for i, c := range text {
+		var d byte
+		switch {
+		case '0' <= c && c <= '9':
+			d = c - '0'
+		case 'a' <= c && c <= 'f':
+			d = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			d = c - 'A' + 10
+		default:
+			return errUint128Text
+		}
+		v[i/16] = v[i/16]<<4 | uint64(d)
+	}
+
+	u.Hi, u.Lo = v[0], v[1]
+
+	return nil
+}
+
+func (u Uint128) appendHex(b []byte) []byte {
+	const digits = "0123456789abcdef"
+
+	for _, x := range [2]uint64{u.Hi, u.Lo} {
+		for shift := 60; shift >= 0; shift -= 4 {
+			b = append(b, digits[(x>>shift)&0xf])
+		}
+	}
+
+	return b
+}
+
+// Hash128 computes a 128-bit rapidhash of the input data using the default
+// seed (0).
+//
+// The block loop is shared with [Hash], but the lanes are folded into two
+// halves that feed two independent finalization chains, so the full 128 bits
+// of state survive to the output. Lo is always equal to [Hash] of the same
+// input.
+func Hash128(data []byte) Uint128 {
+	return hash128(data, 0, &defaultSecrets, variantDefault)
+}
+
+// Hash128WithSeed computes a 128-bit rapidhash of the input data using the
+// provided seed. Lo is always equal to [HashWithSeed] of the same input.
+func Hash128WithSeed(data []byte, seed uint64) Uint128 {
+	return hash128(data, seed, &defaultSecrets, variantDefault)
+}
+
+// HashString128 computes a 128-bit rapidhash of the input string using the
+// default seed (0).
+func HashString128(s string) Uint128 {
+	return Hash128(stringToBytes(s))
+}
+
+// HashString128WithSeed computes a 128-bit rapidhash of the input string
+// using the provided seed.
+func HashString128WithSeed(s string, seed uint64) Uint128 {
+	return Hash128WithSeed(stringToBytes(s), seed)
+}
+
+// hash128 is the 128-bit counterpart of hashWithSecrets.
+func hash128(data []byte, seed uint64, s *Secrets, v variant) Uint128 {
+	length := len(data)
+	seed ^= mix(seed^s[2], s[1])
+
+	var a, b, seed2 uint64
+	i := length
+
+	if length <= 16 {
+		if length >= 4 {
+			p := unsafe.Pointer(unsafe.SliceData(data))
+			if length >= 8 {
+				a = u64(p)
+				b = u64(add(p, uintptr(length-8)))
+			} else {
+				a = u32(p)
+				b = u32(add(p, uintptr(length-4)))
+			}
+			seed ^= uint64(length)
+		} else if length > 0 {
+			a, b = loadUpTo3(data)
+		}
+		seed2 = mix(seed^s[3], s[4])
+	} else {
+		var p unsafe.Pointer
+		var even, odd uint64
+		p, i, even, odd = accumWithSecrets(unsafe.Pointer(unsafe.SliceData(data)), length, seed, s, v)
+		seed, seed2 = hashTail128WithSecrets(p, i, even^odd, mix(even^s[3], odd^s[4]), s)
+
+		origP := unsafe.Pointer(unsafe.SliceData(data))
+		a = u64(add(origP, uintptr(length-16))) ^ uint64(i)
+		b = u64(add(origP, uintptr(length-8)))
+	}
+
+	return finish128(a, b, seed, seed2, i, s)
+}
+
+// hashTail128WithSecrets is hashTailWithSecrets for both chains of the
+// 128-bit hash. seed follows the 64-bit chain exactly; seed2 mixes the same
+// words with different secrets.
+func hashTail128WithSecrets(p unsafe.Pointer, i int, seed, seed2 uint64, s *Secrets) (uint64, uint64) {
+	if i > 16 {
+		seed = mix(u64(p)^s[2], u64(add(p, 8))^seed)
+		seed2 = mix(u64(p)^s[3], u64(add(p, 8))^seed2)
+		if i > 32 {
+			seed = mix(u64(add(p, 16))^s[2], u64(add(p, 24))^seed)
+			seed2 = mix(u64(add(p, 16))^s[4], u64(add(p, 24))^seed2)
+		}
+		if i > 48 {
+			seed = mix(u64(add(p, 32))^s[1], u64(add(p, 40))^seed)
+			seed2 = mix(u64(add(p, 32))^s[5], u64(add(p, 40))^seed2)
+		}
+		if i > 64 {
+			seed = mix(u64(add(p, 48))^s[1], u64(add(p, 56))^seed)
+			seed2 = mix(u64(add(p, 48))^s[6], u64(add(p, 56))^seed2)
+		}
+		if i > 80 {
+			seed = mix(u64(add(p, 64))^s[2], u64(add(p, 72))^seed)
+			seed2 = mix(u64(add(p, 64))^s[3], u64(add(p, 72))^seed2)
+		}
+		if i > 96 {
+			seed = mix(u64(add(p, 80))^s[1], u64(add(p, 88))^seed)
+			seed2 = mix(u64(add(p, 80))^s[4], u64(add(p, 88))^seed2)
+		}
+	}
+
+	return seed, seed2
+}
+
+// finish128 applies the 64-bit finalizer to each chain. Lo matches the 64-bit
+// hash bit for bit.
+func finish128(a, b, seed, seed2 uint64, i int, s *Secrets) Uint128 {
+	a1, b1 := mum(a^s[1], b^seed)
+	a2, b2 := mum(a^s[3], b^seed2)
+
+	return Uint128{
+		Hi: mix(a2^s[7], b2^s[4]^uint64(i)),
+		Lo: mix(a1^s[7], b1^s[1]^uint64(i)),
+	}
+}
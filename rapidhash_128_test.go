@@ -0,0 +1,155 @@
+package rapidhash_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+// Test vectors pinning the 128-bit output format: {size, Hi, Lo}. Lo equals
+// the 64-bit C reference vectors in sizeTestVectors.
+var hash128TestVectors = []struct {
+	size int
+	hi   uint64
+	lo   uint64
+}{
+	{0, 0xcd156300ace815d0, 0x338dc4be2cecdae},
+	{1, 0x2f02829758c6e441, 0x4f23c791b16eba02},
+	{3, 0xe00ed047d4dd96e2, 0xdbd091bcf57ae814},
+	{4, 0x62b0cd4dda070689, 0x46fef26db4943adf},
+	{8, 0xb1928662d288310f, 0xda56413ff396af3e},
+	{16, 0xe97f7d3fb4ead8b7, 0xd6bfc1bcf7e9ca19},
+	{17, 0x259cebda1ee01d0b, 0x7508c9e74d5b5366},
+	{33, 0xac4bd2de238ae703, 0xeb4ff8393398a779},
+	{80, 0x87ee137ae25a2784, 0xe7e477a0dffeae1f},
+	{112, 0x2abcfedb8e2a0e9b, 0x667174637fd34ae7},
+	{113, 0x5892a18cf9089858, 0xabaf0e2bdacf7e23},
+	{449, 0x40d144a98eb3aeaa, 0x6be60a96ed7a9b38},
+	{1000, 0xed90b028b5ae47cf, 0x2a6be558a956faf3},
+}
+
+func TestHash128Vectors(t *testing.T) {
+	for _, tc := range hash128TestVectors {
+		data := make([]byte, tc.size)
+		for i := range data {
+			data[i] = byte(i % 256)
+		}
+
+		want := rapidhash.Uint128{Hi: tc.hi, Lo: tc.lo}
+		if got := rapidhash.Hash128(data); got != want {
+			t.Errorf("size=%d: Hash128() = %v, want %v", tc.size, got, want)
+		}
+		if got := rapidhash.HashString128(string(data)); got != want {
+			t.Errorf("size=%d: HashString128() = %v, want %v", tc.size, got, want)
+		}
+	}
+}
+
+func TestHash128LoMatchesHashWithSeed(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 4, 7, 8, 15, 16, 17, 32, 48, 64, 96, 97, 112, 113, 224, 448, 449, 1000} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*11 + 7)
+		}
+
+		for _, seed := range []uint64{0, 1, 0xdeadbeef} {
+			h := rapidhash.Hash128WithSeed(data, seed)
+			if want := rapidhash.HashWithSeed(data, seed); h.Lo != want {
+				t.Errorf("size=%d seed=%d: Hash128WithSeed().Lo = 0x%x, want 0x%x", size, seed, h.Lo, want)
+			}
+			if h.Hi == h.Lo {
+				t.Errorf("size=%d seed=%d: Hi == Lo (0x%x)", size, seed, h.Hi)
+			}
+			if got := rapidhash.HashString128WithSeed(string(data), seed); got != h {
+				t.Errorf("size=%d seed=%d: HashString128WithSeed() = %v, want %v", size, seed, got, h)
+			}
+		}
+	}
+}
+
+func TestHash128HiBitFlipSensitivity(t *testing.T) {
+	// Lo alone collapses long inputs into 64 bits of state before the tail;
+	// Hi must react to changes anywhere in the input too.
+	data := make([]byte, 600)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	base := rapidhash.Hash128(data)
+
+	for _, idx := range []int{0, 1, 111, 112, 300, 487, 488, 599} {
+		data[idx] ^= 1
+		if got := rapidhash.Hash128(data); got.Hi == base.Hi {
+			t.Errorf("flipping byte %d did not change Hi", idx)
+		}
+		data[idx] ^= 1
+	}
+}
+
+func TestHasherSum128(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 5)
+	}
+
+	for _, size := range []int{0, 5, 16, 17, 112, 113, 300, 1000} {
+		for _, chunk := range []int{1, 13, 112, 1000} {
+			h := rapidhash.NewWithSeed(99)
+			for i := 0; i < size; i += chunk {
+				end := i + chunk
+				if end > size {
+					end = size
+				}
+				_, _ = h.Write(data[i:end])
+			}
+
+			want := rapidhash.Hash128WithSeed(data[:size], 99)
+			if got := h.Sum128(); got != want {
+				t.Errorf("size=%d chunk=%d: Sum128() = %v, want %v", size, chunk, got, want)
+			}
+		}
+	}
+
+	// Micro and Nano hashers keep Lo compatible with their 64-bit variant.
+	for _, h := range []*rapidhash.Hasher{rapidhash.NewMicro(), rapidhash.NewNano()} {
+		_, _ = h.Write(data)
+		if got := h.Sum128(); got.Lo != h.Sum64() {
+			t.Errorf("BlockSize %d: Sum128().Lo = 0x%x, want 0x%x", h.BlockSize(), got.Lo, h.Sum64())
+		}
+	}
+}
+
+func TestUint128Text(t *testing.T) {
+	u := rapidhash.Uint128{Hi: 0x0123456789abcdef, Lo: 0xfedcba9876543210}
+	const want = "0123456789abcdeffedcba9876543210"
+
+	if got := u.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	text, err := u.MarshalText()
+	if err != nil || string(text) != want {
+		t.Errorf("MarshalText() = %q, %v, want %q", text, err, want)
+	}
+
+	var back rapidhash.Uint128
+	if err := back.UnmarshalText([]byte("0123456789ABCDEFfedcba9876543210")); err != nil || back != u {
+		t.Errorf("UnmarshalText() = %v, %v, want %v", back, err, u)
+	}
+
+	for _, bad := range []string{"", "0123", want + "0", "0123456789abcdeffedcba987654321g"} {
+		if err := back.UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded, want error", bad)
+		}
+	}
+
+	// Comparable and usable as a JSON map key via its text form.
+	m := map[rapidhash.Uint128]int{u: 1}
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(encoded) != `{"`+want+`":1}` {
+		t.Errorf("json.Marshal() = %s", encoded)
+	}
+}
@@ -0,0 +1,289 @@
+package rapidhash
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// batchWidth is the number of keys hashed in lockstep by the batch functions.
+const batchWidth = 4
+
+var errBatchOut = errors.New("rapidhash: batch output slice shorter than keys")
+
+// HashBatch computes [Hash] of every key, storing the result for keys[i] in
+// out[i]. It panics if out is shorter than keys.
+//
+// Keys are processed in groups of 4 whose multiply chains are interleaved.
+// Keys of up to 64 bytes hash with up to twice the throughput of calling
+// [Hash] in a loop. Groups that mix short and long keys fall back to the
+// scalar path; results are always identical to [Hash].
+func HashBatch(keys [][]byte, out []uint64) {
+	hashBatch(keys, out, 0, variantDefault)
+}
+
+// HashBatchWithSeed computes [HashWithSeed] of every key with the given seed,
+// storing the result for keys[i] in out[i]. It panics if out is shorter than
+// keys.
+func HashBatchWithSeed(keys [][]byte, out []uint64, seed uint64) {
+	hashBatch(keys, out, seed, variantDefault)
+}
+
+// HashMicroBatch computes [HashMicro] of every key, storing the result for
+// keys[i] in out[i]. It panics if out is shorter than keys.
+func HashMicroBatch(keys [][]byte, out []uint64) {
+	hashBatch(keys, out, 0, variantMicro)
+}
+
+// HashMicroBatchWithSeed computes [HashMicroWithSeed] of every key with the
+// given seed, storing the result for keys[i] in out[i]. It panics if out is
+// shorter than keys.
+func HashMicroBatchWithSeed(keys [][]byte, out []uint64, seed uint64) {
+	hashBatch(keys, out, seed, variantMicro)
+}
+
+// HashNanoBatch computes [HashNano] of every key, storing the result for
+// keys[i] in out[i]. It panics if out is shorter than keys.
+func HashNanoBatch(keys [][]byte, out []uint64) {
+	hashBatch(keys, out, 0, variantNano)
+}
+
+// HashNanoBatchWithSeed computes [HashNanoWithSeed] of every key with the
+// given seed, storing the result for keys[i] in out[i]. It panics if out is
+// shorter than keys.
+func HashNanoBatchWithSeed(keys [][]byte, out []uint64, seed uint64) {
+	hashBatch(keys, out, seed, variantNano)
+}
+
+// HashStringBatch computes [HashString] of every key, storing the result for
+// keys[i] in out[i]. It panics if out is shorter than keys.
+func HashStringBatch(keys []string, out []uint64) {
+	hashStringBatch(keys, out, 0, variantDefault)
+}
+
+// HashStringBatchWithSeed computes [HashStringWithSeed] of every key with the
+// given seed, storing the result for keys[i] in out[i]. It panics if out is
+// shorter than keys.
+func HashStringBatchWithSeed(keys []string, out []uint64, seed uint64) {
+	hashStringBatch(keys, out, seed, variantDefault)
+}
+
+// HashStringMicroBatch computes [HashStringMicro] of every key, storing the
+// result for keys[i] in out[i]. It panics if out is shorter than keys.
+func HashStringMicroBatch(keys []string, out []uint64) {
+	hashStringBatch(keys, out, 0, variantMicro)
+}
+
+// HashStringMicroBatchWithSeed computes [HashStringMicroWithSeed] of every
+// key with the given seed, storing the result for keys[i] in out[i]. It
+// panics if out is shorter than keys.
+func HashStringMicroBatchWithSeed(keys []string, out []uint64, seed uint64) {
+	hashStringBatch(keys, out, seed, variantMicro)
+}
+
+// HashStringNanoBatch computes [HashStringNano] of every key, storing the
+// result for keys[i] in out[i]. It panics if out is shorter than keys.
+func HashStringNanoBatch(keys []string, out []uint64) {
+	hashStringBatch(keys, out, 0, variantNano)
+}
+
+// HashStringNanoBatchWithSeed computes [HashStringNanoWithSeed] of every key
+// with the given seed, storing the result for keys[i] in out[i]. It panics if
+// out is shorter than keys.
+func HashStringNanoBatchWithSeed(keys []string, out []uint64, seed uint64) {
+	hashStringBatch(keys, out, seed, variantNano)
+}
+
+func hashBatch(keys [][]byte, out []uint64, seed uint64, v variant) {
+	if len(out) < len(keys) {
+		panic(errBatchOut)
+	}
+
+	// The seed is mixed once for the whole batch instead of once per key.
+	mixed := seed ^ mix(seed^secret2, secret1)
+
+	i := 0
+	for ; i+batchWidth <= len(keys); i += batchWidth {
+		hash4((*[batchWidth][]byte)(keys[i:i+batchWidth]), (*[batchWidth]uint64)(out[i:i+batchWidth]), seed, mixed, v)
+	}
+	for ; i < len(keys); i++ {
+		out[i] = hashVariant(keys[i], seed, v)
+	}
+}
+
+func hashStringBatch(keys []string, out []uint64, seed uint64, v variant) {
+	if len(out) < len(keys) {
+		panic(errBatchOut)
+	}
+
+	mixed := seed ^ mix(seed^secret2, secret1)
+
+	i := 0
+	for ; i+batchWidth <= len(keys); i += batchWidth {
+		k := (*[batchWidth]string)(keys[i : i+batchWidth])
+		group := [batchWidth][]byte{
+			stringToBytes(k[0]),
+			stringToBytes(k[1]),
+			stringToBytes(k[2]),
+			stringToBytes(k[3]),
+		}
+		hash4(&group, (*[batchWidth]uint64)(out[i:i+batchWidth]), seed, mixed, v)
+	}
+	for ; i < len(keys); i++ {
+		out[i] = hashVariant(stringToBytes(keys[i]), seed, v)
+	}
+}
+
+// hashVariant is the scalar fallback of the batch functions.
+func hashVariant(data []byte, seed uint64, v variant) uint64 {
+	switch v {
+	case variantMicro:
+		return HashMicroWithSeed(data, seed)
+	case variantNano:
+		return HashNanoWithSeed(data, seed)
+	}
+
+	return HashWithSeed(data, seed)
+}
+
+// hash4 hashes a group of 4 keys. All variants agree up to 48 bytes, and the
+// default and Micro variants up to 80, so the lockstep paths are shared.
+func hash4(k *[batchWidth][]byte, out *[batchWidth]uint64, seed, mixed uint64, v variant) {
+	l0, l1, l2, l3 := len(k[0]), len(k[1]), len(k[2]), len(k[3])
+
+	if uint(l0-8) <= 8 && uint(l1-8) <= 8 && uint(l2-8) <= 8 && uint(l3-8) <= 8 {
+		hashWords4(k, out, mixed)
+
+		return
+	}
+	if l0 <= 16 && l1 <= 16 && l2 <= 16 && l3 <= 16 {
+		hashSmall4(k, out, mixed)
+
+		return
+	}
+
+	limit := 64
+	if v == variantNano {
+		limit = 48
+	}
+	if l0 > 16 && l1 > 16 && l2 > 16 && l3 > 16 &&
+		l0 <= limit && l1 <= limit && l2 <= limit && l3 <= limit {
+		hashMedium4(k, out, mixed)
+
+		return
+	}
+
+	for i := range k {
+		out[i] = hashVariant(k[i], seed, v)
+	}
+}
+
+// loadSmall returns the two input words and the length-adjusted seed for a
+// key of at most 16 bytes, as in the small path of [HashWithSeed].
+func loadSmall(data []byte, seed uint64) (uint64, uint64, uint64) {
+	length := len(data)
+	if length >= 4 {
+		p := unsafe.Pointer(unsafe.SliceData(data))
+		if length >= 8 {
+			return u64(p), u64(add(p, uintptr(length-8))), seed ^ uint64(length)
+		}
+
+		return u32(p), u32(add(p, uintptr(length-4))), seed ^ uint64(length)
+	}
+	if length > 0 {
+		a, b := loadUpTo3(data)
+
+		return a, b, seed
+	}
+
+	return 0, 0, seed
+}
+
+// hashSmall4 hashes 4 keys of at most 16 bytes. Loads are done first so the
+// 4 independent multiply chains can be in flight together.
+func hashSmall4(k *[batchWidth][]byte, out *[batchWidth]uint64, mixed uint64) {
+	a0, b0, s0 := loadSmall(k[0], mixed)
+	a1, b1, s1 := loadSmall(k[1], mixed)
+	a2, b2, s2 := loadSmall(k[2], mixed)
+	a3, b3, s3 := loadSmall(k[3], mixed)
+
+	a0, b0 = mum(a0^secret1, b0^s0)
+	a1, b1 = mum(a1^secret1, b1^s1)
+	a2, b2 = mum(a2^secret1, b2^s2)
+	a3, b3 = mum(a3^secret1, b3^s3)
+
+	out[0] = mix(a0^secret7, b0^secret1^uint64(len(k[0])))
+	out[1] = mix(a1^secret7, b1^secret1^uint64(len(k[1])))
+	out[2] = mix(a2^secret7, b2^secret1^uint64(len(k[2])))
+	out[3] = mix(a3^secret7, b3^secret1^uint64(len(k[3])))
+}
+
+// hashWords4 hashes 4 keys of 8 to 16 bytes, whose input words are their
+// first and last 8 bytes, without branching per key.
+func hashWords4(k *[batchWidth][]byte, out *[batchWidth]uint64, mixed uint64) {
+	p0 := unsafe.Pointer(unsafe.SliceData(k[0]))
+	p1 := unsafe.Pointer(unsafe.SliceData(k[1]))
+	p2 := unsafe.Pointer(unsafe.SliceData(k[2]))
+	p3 := unsafe.Pointer(unsafe.SliceData(k[3]))
+	n0, n1, n2, n3 := uint64(len(k[0])), uint64(len(k[1])), uint64(len(k[2])), uint64(len(k[3]))
+
+	a0, b0 := mum(u64(p0)^secret1, u64(add(p0, uintptr(n0-8)))^mixed^n0)
+	a1, b1 := mum(u64(p1)^secret1, u64(add(p1, uintptr(n1-8)))^mixed^n1)
+	a2, b2 := mum(u64(p2)^secret1, u64(add(p2, uintptr(n2-8)))^mixed^n2)
+	a3, b3 := mum(u64(p3)^secret1, u64(add(p3, uintptr(n3-8)))^mixed^n3)
+
+	out[0] = mix(a0^secret7, b0^secret1^n0)
+	out[1] = mix(a1^secret7, b1^secret1^n1)
+	out[2] = mix(a2^secret7, b2^secret1^n2)
+	out[3] = mix(a3^secret7, b3^secret1^n3)
+}
+
+// hashMedium4 hashes 4 keys of 17 to 64 bytes, stepping the 16-byte tail
+// chains of all keys together.
+func hashMedium4(k *[batchWidth][]byte, out *[batchWidth]uint64, mixed uint64) {
+	l0, l1, l2, l3 := len(k[0]), len(k[1]), len(k[2]), len(k[3])
+	p0 := unsafe.Pointer(unsafe.SliceData(k[0]))
+	p1 := unsafe.Pointer(unsafe.SliceData(k[1]))
+	p2 := unsafe.Pointer(unsafe.SliceData(k[2]))
+	p3 := unsafe.Pointer(unsafe.SliceData(k[3]))
+
+	s0 := mix(u64(p0)^secret2, u64(add(p0, 8))^mixed)
+	s1 := mix(u64(p1)^secret2, u64(add(p1, 8))^mixed)
+	s2 := mix(u64(p2)^secret2, u64(add(p2, 8))^mixed)
+	s3 := mix(u64(p3)^secret2, u64(add(p3, 8))^mixed)
+
+	if l0 > 32 {
+		s0 = mix(u64(add(p0, 16))^secret2, u64(add(p0, 24))^s0)
+	}
+	if l1 > 32 {
+		s1 = mix(u64(add(p1, 16))^secret2, u64(add(p1, 24))^s1)
+	}
+	if l2 > 32 {
+		s2 = mix(u64(add(p2, 16))^secret2, u64(add(p2, 24))^s2)
+	}
+	if l3 > 32 {
+		s3 = mix(u64(add(p3, 16))^secret2, u64(add(p3, 24))^s3)
+	}
+
+	if l0 > 48 {
+		s0 = mix(u64(add(p0, 32))^secret1, u64(add(p0, 40))^s0)
+	}
+	if l1 > 48 {
+		s1 = mix(u64(add(p1, 32))^secret1, u64(add(p1, 40))^s1)
+	}
+	if l2 > 48 {
+		s2 = mix(u64(add(p2, 32))^secret1, u64(add(p2, 40))^s2)
+	}
+	if l3 > 48 {
+		s3 = mix(u64(add(p3, 32))^secret1, u64(add(p3, 40))^s3)
+	}
+
+	a0, b0 := mum(u64(add(p0, uintptr(l0-16)))^uint64(l0)^secret1, u64(add(p0, uintptr(l0-8)))^s0)
+	a1, b1 := mum(u64(add(p1, uintptr(l1-16)))^uint64(l1)^secret1, u64(add(p1, uintptr(l1-8)))^s1)
+	a2, b2 := mum(u64(add(p2, uintptr(l2-16)))^uint64(l2)^secret1, u64(add(p2, uintptr(l2-8)))^s2)
+	a3, b3 := mum(u64(add(p3, uintptr(l3-16)))^uint64(l3)^secret1, u64(add(p3, uintptr(l3-8)))^s3)
+
+	out[0] = mix(a0^secret7, b0^secret1^uint64(l0))
+	out[1] = mix(a1^secret7, b1^secret1^uint64(l1))
+	out[2] = mix(a2^secret7, b2^secret1^uint64(l2))
+	out[3] = mix(a3^secret7, b3^secret1^uint64(l3))
+}
@@ -0,0 +1,37 @@
+package rapidhash_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func BenchmarkHashBatch(b *testing.B) {
+	const n = 1024
+	rng := rand.New(rand.NewSource(1))
+
+	for _, size := range []int{8, 16, 32, 64} {
+		keys := makeBatchKeys(rng, n, size, size)
+		out := make([]uint64, n)
+
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			b.Run("Hash", func(b *testing.B) {
+				b.SetBytes(int64(n * size))
+				for i := 0; i < b.N; i++ {
+					for j, k := range keys {
+						out[j] = rapidhash.Hash(k)
+					}
+				}
+			})
+
+			b.Run("HashBatch", func(b *testing.B) {
+				b.SetBytes(int64(n * size))
+				for i := 0; i < b.N; i++ {
+					rapidhash.HashBatch(keys, out)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package rapidhash_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func makeBatchKeys(rng *rand.Rand, n, minLen, maxLen int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, minLen+rng.Intn(maxLen-minLen+1))
+		rng.Read(keys[i])
+	}
+	return keys
+}
+
+func TestHashBatchMatchesScalar(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+
+	ranges := []struct{ min, max int }{
+		{0, 3}, {0, 16}, {8, 8}, {8, 16}, {17, 48}, {17, 64}, {49, 64}, {33, 80}, {0, 200}, {100, 1000},
+	}
+	variants := []struct {
+		name   string
+		batch  func([][]byte, []uint64, uint64)
+		sbatch func([]string, []uint64, uint64)
+		scalar func([]byte, uint64) uint64
+	}{
+		{"Hash", rapidhash.HashBatchWithSeed, rapidhash.HashStringBatchWithSeed, rapidhash.HashWithSeed},
+		{"Micro", rapidhash.HashMicroBatchWithSeed, rapidhash.HashStringMicroBatchWithSeed, rapidhash.HashMicroWithSeed},
+		{"Nano", rapidhash.HashNanoBatchWithSeed, rapidhash.HashStringNanoBatchWithSeed, rapidhash.HashNanoWithSeed},
+	}
+
+	for _, r := range ranges {
+		for _, n := range []int{0, 1, 3, 4, 5, 8, 33} {
+			keys := makeBatchKeys(rng, n, r.min, r.max)
+			strs := make([]string, n)
+			for i, k := range keys {
+				strs[i] = string(k)
+			}
+
+			for _, v := range variants {
+				for _, seed := range []uint64{0, 0xabcdef} {
+					out := make([]uint64, n)
+					sout := make([]uint64, n)
+					v.batch(keys, out, seed)
+					v.sbatch(strs, sout, seed)
+
+					for i, k := range keys {
+						want := v.scalar(k, seed)
+						if out[i] != want {
+							t.Errorf("%s len=%d seed=%d: batch = 0x%x, want 0x%x", v.name, len(k), seed, out[i], want)
+						}
+						if sout[i] != want {
+							t.Errorf("%s len=%d seed=%d: string batch = 0x%x, want 0x%x", v.name, len(k), seed, sout[i], want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHashBatchDefaultSeed(t *testing.T) {
+	keys := [][]byte{[]byte("a"), []byte("hello"), []byte("The quick brown fox"), nil, []byte("abcdefghijklmnop")}
+	strs := []string{"a", "hello", "The quick brown fox", "", "abcdefghijklmnop"}
+	out := make([]uint64, len(keys))
+
+	rapidhash.HashBatch(keys, out)
+	for i, k := range keys {
+		if want := rapidhash.Hash(k); out[i] != want {
+			t.Errorf("HashBatch[%d] = 0x%x, want 0x%x", i, out[i], want)
+		}
+	}
+
+	rapidhash.HashStringBatch(strs, out)
+	for i, s := range strs {
+		if want := rapidhash.HashString(s); out[i] != want {
+			t.Errorf("HashStringBatch[%d] = 0x%x, want 0x%x", i, out[i], want)
+		}
+	}
+
+	rapidhash.HashMicroBatch(keys, out)
+	rapidhash.HashNanoBatch(keys, out)
+	rapidhash.HashStringMicroBatch(strs, out)
+	rapidhash.HashStringNanoBatch(strs, out)
+	for i, s := range strs {
+		if want := rapidhash.HashStringNano(s); out[i] != want {
+			t.Errorf("HashStringNanoBatch[%d] = 0x%x, want 0x%x", i, out[i], want)
+		}
+	}
+}
+
+func TestHashBatchShortOutputPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("HashBatch with short output did not panic")
+		}
+	}()
+
+	rapidhash.HashBatch(make([][]byte, 5), make([]uint64, 4))
+}
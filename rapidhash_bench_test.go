@@ -65,3 +65,56 @@ func BenchmarkComputes(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkAccumBlocks compares the block kernel selected for this build
+// (assembly on amd64) with the portable Go loop.
+func BenchmarkAccumBlocks(b *testing.B) {
+	secrets := rapidhash.MakeSecrets(0)
+
+	for _, size := range []int{1024, 8192} {
+		data := makeData(size + 1)
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			b.Run("Kernel", func(b *testing.B) {
+				b.SetBytes(int64(size))
+				for i := 0; i < b.N; i++ {
+					_, lanes := rapidhash.AccumBlocks(data, 0, &secrets)
+					sink = lanes[0]
+				}
+			})
+
+			b.Run("Generic", func(b *testing.B) {
+				b.SetBytes(int64(size))
+				for i := 0; i < b.N; i++ {
+					_, lanes := rapidhash.AccumBlocksGeneric(data, 0, &secrets)
+					sink = lanes[0]
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkVariants compares the bulk throughput of the three variants.
+func BenchmarkVariants(b *testing.B) {
+	variants := []struct {
+		name string
+		fn   func([]byte) uint64
+	}{
+		{"Hash", rapidhash.Hash},
+		{"HashMicro", rapidhash.HashMicro},
+		{"HashNano", rapidhash.HashNano},
+	}
+
+	for _, size := range []int{1024, 8192, 65536} {
+		data := makeData(size)
+		b.Run(fmt.Sprintf("%d", size), func(b *testing.B) {
+			for _, v := range variants {
+				b.Run(v.name, func(b *testing.B) {
+					b.SetBytes(int64(size))
+					for i := 0; i < b.N; i++ {
+						sink = v.fn(data)
+					}
+				})
+			}
+		})
+	}
+}
@@ -3,17 +3,56 @@ package rapidhash
 import (
 	"crypto/rand"
 	"encoding/binary"
-	"errors"
 	"math"
 	"reflect"
 )
 
+// ComparableEncoding is the version of the byte encoding that the comparable
+// functions hash. Version 2 is injective: two values encode to the same bytes
+// only if they are == (treating all NaNs as equal in the stable functions).
+//
+// A value is encoded as a marker byte, 0 for a nil interface and 1
+// otherwise, followed by its type name as a uvarint length and the name, and
+// then its contents:
+//   - bool and 8, 16, 32 and 64-bit numbers: their little-endian bytes,
+//     with every zero float encoded as +0;
+//   - int, uint and uintptr: 8 little-endian bytes on every platform;
+//   - complex numbers: the real part, then the imaginary part;
+//   - strings: the length as a uvarint, then the bytes;
+//   - arrays and structs: each element or field in order;
+//   - interfaces: the encoding of the dynamic value, from its marker byte;
+//   - pointers, channels and unsafe pointers: the address as 8 bytes;
+//   - [Hashable] values: the Sum64 of what HashTo wrote, as 8 bytes.
+//
+// Struct fields can be tagged to change their encoding:
+//   - rapidhash:"-" leaves the field out;
+//   - rapidhash:"fold" encodes a string field with ASCII letters in lower
+//     case, for case-insensitive keys;
+//   - rapidhash:"ignorezero" leaves the field out while it is == to its zero
+//     value. In the top-level value, such fields come after all other
+//     fields, each preceded by its index in the struct as a uvarint, so that
+//     adding one keeps the hashes of existing values. Inside another value,
+//     as in a nested struct, an array or an interface, the field is preceded
+//     by a byte, 0 if it is left out and 1 otherwise.
+//
+// Options are separated by commas, as in rapidhash:"fold,ignorezero".
+//
+// Only the dynamic types of interfaces and the top-level value are named.
+// Type names are written as in Go, except that named types and unexported
+// field and method names are qualified by their full package path, as in
+// *text/template.Template. Types declared inside functions cannot be told
+// apart from other types of the same name in their package, so values of
+// two such types that are otherwise encoded alike encode the same. [HashComparableWithSeed]
+// skips the encoding for types compared as plain memory.
+const ComparableEncoding = 2
+
 // HashComparable returns the hash of comparable value v using the default seed (0).
 //
 // This is not compatible with [Hash] or [HashWithSeed] because it encodes type
-// information and traverses values via reflection; it also randomizes
+// information along with the value; it also randomizes
 // floating-point NaNs (so results are not deterministic when v contains NaNs)
 // and hashes pointer-like values by address, making results process-specific.
+// Use [HashComparableStable] for results that can be persisted.
 func HashComparable[T comparable](v T) uint64 {
 	return HashComparableWithSeed(v, 0)
 }
@@ -21,76 +60,89 @@ func HashComparable[T comparable](v T) uint64 {
 // HashComparableWithSeed returns the hash of comparable value v using seed.
 //
 // This is not compatible with [Hash] or [HashWithSeed] because it encodes type
-// information and traverses values via reflection; it also randomizes
+// information along with the value; it also randomizes
 // floating-point NaNs (so results are not deterministic when v contains NaNs)
 // and hashes pointer-like values by address, making results process-specific.
+//
+// If == on T is plain memory equality, as for booleans, integers, and arrays
+// and structs of them with no padding or blank fields, v is hashed as its
+// memory: the result is [HashWithSeed] of its unsafe.Sizeof(v) bytes, not of
+// its [ComparableEncoding], and differs from the hash of any(v).
 func HashComparableWithSeed[T comparable](v T, seed uint64) uint64 {
-	var stack [256]byte
-	buf := stack[:0]
-	buf = appendComparableBytes(buf, reflect.ValueOf(v))
+	h, err := hashComparable(&v, seed, false)
+	if err != nil {
+		panic(unhashable(err))
+	}
 
-	return HashWithSeed(buf, seed)
+	return h
 }
 
-func appendComparableBytes(buf []byte, v reflect.Value) []byte {
-	return appendValueBytes(buf, v)
+// canonicalNaN and canonicalNaN32 are the encodings of every NaN in the
+// stable comparable functions: the bits of [math.NaN] as a float64 and as a
+// float32.
+const (
+	canonicalNaN   = 0x7ff8000000000001
+	canonicalNaN32 = 0x7fc00000
+)
+
+// UnsupportedTypeError is returned by the stable comparable functions for a
+// value whose hash would depend on a memory address (pointers, channels and
+// unsafe pointers) or that cannot be hashed at all.
+type UnsupportedTypeError struct {
+	Type reflect.Type
 }
 
-func appendValueBytes(buf []byte, v reflect.Value) []byte {
-	buf = append(buf, v.Type().String()...)
-
-	switch v.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return appendUint64LE(buf, uint64(v.Int()))
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return appendUint64LE(buf, v.Uint())
-	case reflect.Array:
-		var tmp [8]byte
-		for i := 0; i < v.Len(); i++ {
-			binary.LittleEndian.PutUint64(tmp[:], uint64(i))
-			buf = append(buf, tmp[:]...)
-			buf = appendValueBytes(buf, v.Index(i))
-		}
-		return buf
-	case reflect.String:
-		return append(buf, v.String()...)
-	case reflect.Struct:
-		var tmp [8]byte
-		for i := 0; i < v.NumField(); i++ {
-			binary.LittleEndian.PutUint64(tmp[:], uint64(i))
-			buf = append(buf, tmp[:]...)
-			buf = appendValueBytes(buf, v.Field(i))
-		}
-		return buf
-	case reflect.Complex64, reflect.Complex128:
-		c := v.Complex()
-		buf = appendFloat64Bytes(buf, real(c))
-		buf = appendFloat64Bytes(buf, imag(c))
-		return buf
-	case reflect.Float32, reflect.Float64:
-		return appendFloat64Bytes(buf, v.Float())
-	case reflect.Bool:
-		if v.Bool() {
-			return append(buf, 1)
+func (e *UnsupportedTypeError) Error() string {
+	return "rapidhash: no stable hash for type " + e.Type.String()
+}
+
+// HashComparableStable returns a process-stable hash of comparable value v
+// using the default seed (0).
+//
+// It uses the encoding of [HashComparable], described at [ComparableEncoding],
+// except that every NaN hashes the same and values holding a pointer, channel or
+// unsafe pointer anywhere are rejected with an [*UnsupportedTypeError]. The
+// result only depends on v, so it is the same across processes, builds and
+// GOARCH values and can be persisted.
+func HashComparableStable[T comparable](v T) (uint64, error) {
+	return HashComparableStableWithSeed(v, 0)
+}
+
+// HashComparableStableWithSeed returns a process-stable hash of comparable
+// value v using seed. See [HashComparableStable].
+func HashComparableStableWithSeed[T comparable](v T, seed uint64) (uint64, error) {
+	return hashComparable(&v, seed, true)
+}
+
+// float32Bits returns the encoded bits of f: +0 for either zero, and a
+// canonical or random NaN depending on stable.
+func float32Bits(f float32, stable bool) uint32 {
+	switch {
+	case f == 0:
+		return 0
+	case math.IsNaN(float64(f)):
+		if stable {
+			return canonicalNaN32
 		}
-		return append(buf, 0)
-	case reflect.UnsafePointer, reflect.Pointer, reflect.Chan:
-		return appendUint64LE(buf, uint64(v.Pointer()))
-	case reflect.Interface:
-		return appendValueBytes(buf, v.Elem())
+		return uint32(randUint64())
 	}
 
-	panic(errors.New("rapidhash: hash of unhashable type " + v.Type().String()))
+	return math.Float32bits(f)
 }
 
-func appendFloat64Bytes(buf []byte, f float64) []byte {
-	if f == 0 {
-		return append(buf, 0)
-	}
-	if math.IsNaN(f) {
-		return appendUint64LE(buf, randUint64())
+// float64Bits is float32Bits for a float64.
+func float64Bits(f float64, stable bool) uint64 {
+	switch {
+	case f == 0:
+		return 0
+	case math.IsNaN(f):
+		if stable {
+			return canonicalNaN
+		}
+		return randUint64()
 	}
-	return appendUint64LE(buf, math.Float64bits(f))
+
+	return math.Float64bits(f)
 }
 
 func appendUint64LE(buf []byte, x uint64) []byte {
@@ -2,6 +2,7 @@ package rapidhash_test
 
 import (
 	"testing"
+	"unsafe"
 
 	"go.dw1.io/rapidhash"
 )
@@ -61,3 +62,90 @@ func hashComparableWithSeedAnyBench(v any, seed uint64) uint64 {
 		panic("unsupported test type")
 	}
 }
+
+type (
+	benchKey struct {
+		ID    uint64
+		Shard uint32
+		Kind  uint16
+		Flags uint16
+		Name  [16]byte
+	}
+	benchStringKey struct {
+		ID     uint64
+		Tenant string
+		Name   string
+	}
+	benchFloatKey struct {
+		ID   uint64
+		X, Y float64
+	}
+	benchIfaceKey struct {
+		ID    uint64
+		Value any
+	}
+	benchTaggedKey struct {
+		ID    uint64
+		Name  string `rapidhash:"fold"`
+		Label string `rapidhash:"ignorezero"`
+		Cache uint64 `rapidhash:"-"`
+	}
+)
+
+// BenchmarkComparableStruct compares hashing a struct through its compiled
+// encoder with hashing its raw memory. benchKey has no padding or special
+// fields, so its encoder hashes the memory too; the other keys run encoder
+// programs for their strings, floats, interfaces and tagged fields.
+func BenchmarkComparableStruct(b *testing.B) {
+	k := benchKey{ID: 1 << 40, Shard: 7, Kind: 2, Flags: 1}
+	copy(k.Name[:], "rapidhash-bench")
+
+	b.Run("comparable", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashComparable(k)
+		}
+	})
+	b.Run("any", func(b *testing.B) {
+		v := any(k)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashComparable(v)
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.Hash(unsafe.Slice((*byte)(unsafe.Pointer(&k)), unsafe.Sizeof(k)))
+		}
+	})
+
+	sk := benchStringKey{ID: 1 << 40, Tenant: "acme", Name: "rapidhash-bench"}
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashComparable(sk)
+		}
+	})
+	fk := benchFloatKey{ID: 1 << 40, X: 1.5, Y: -0.25}
+	b.Run("float", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashComparable(fk)
+		}
+	})
+	ik := benchIfaceKey{ID: 1 << 40, Value: "rapidhash-bench"}
+	b.Run("interface", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashComparable(ik)
+		}
+	})
+	tk := benchTaggedKey{ID: 1 << 40, Name: "RapidHash-Bench", Cache: 99}
+	b.Run("tagged", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashComparable(tk)
+		}
+	})
+}
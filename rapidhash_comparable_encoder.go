@@ -0,0 +1,940 @@
+package rapidhash
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// typeEncoder is the compiled [ComparableEncoding] of one type: a flat
+// program of typed memory reads, built the first time the type is seen.
+type typeEncoder struct {
+	tag    string // marker byte and length-prefixed type name
+	ops    []encOp
+	direct bool // the value is stored in the data word of an interface
+	deep   bool // compiled for HashValue
+
+	// rtype is the type word of an interface holding the type, and ptrType
+	// that of a pointer to it, as keys of ifaceCache and genericCache.
+	rtype, ptrType unsafe.Pointer
+
+	// small is the length of the encoding if it is the same for every
+	// value and at most smallEncoding bytes, or 0.
+	small int
+
+	// raw reports whether == on the type is equality of its size bytes of
+	// memory, see rawEqual, and custom whether it holds a Hashable value or
+	// a field with a rapidhash tag.
+	raw, custom bool
+	size        uintptr
+
+	// addr is the first pointer, channel or unsafe pointer type found
+	// outside interfaces, and bad the first type that cannot be hashed.
+	addr, bad reflect.Type
+
+	// nesting counts the structs and arrays being compiled.
+	nesting int
+}
+
+type opCode uint8
+
+const (
+	op8          opCode = iota // bool, int8, uint8
+	op16                       // int16, uint16
+	op32                       // int32, uint32
+	op64                       // int64, uint64
+	opInt                      // int, widened to 8 bytes
+	opUint                     // uint and uintptr, widened to 8 bytes
+	opFloat32                  // float32
+	opFloat64                  // float64
+	opComplex64                // complex64
+	opComplex128               // complex128
+	opString                   // string
+	opBytes                    // n bytes copied as they are, see mergeOps
+	opArray                    // n elements of sub, stride bytes apart
+	opAddr                     // pointer, channel or unsafe pointer
+	opEface                    // empty interface
+	opIface                    // interface of type typ, with methods
+	opHashable                 // value hashed by hash, see Hashable
+	opFoldString               // string with a fold tag
+	opIgnoreZero               // field index n and sub, unless sub is zero; an ignorezero tag of the top-level value
+	opPresent                  // presence byte, then sub unless it is zero; an ignorezero tag elsewhere
+	opPointer                  // pointer to typ, followed by HashValue
+	opSlice                    // slice of typ, for HashValue
+	opByteSlice                // slice of bytes, for HashValue
+	opMap                      // map of type typ, for HashValue
+)
+
+type encOp struct {
+	code   opCode
+	off    uintptr
+	n      int
+	stride uintptr
+	sub    []encOp
+	typ    reflect.Type
+	hash   func(h *Hasher, p unsafe.Pointer)
+}
+
+// opWidth is the length of the encoding of each fixed-width op.
+var opWidth = [opMap + 1]int{
+	op8:          1,
+	op16:         2,
+	op32:         4,
+	op64:         8,
+	opInt:        8,
+	opUint:       8,
+	opFloat32:    4,
+	opFloat64:    8,
+	opComplex64:  8,
+	opComplex128: 16,
+	opAddr:       8,
+}
+
+// smallEncoding is the longest fixed-length encoding that is built in a
+// small buffer and hashed in one call, without an encState.
+const smallEncoding = 64
+
+// maxUnroll is the largest number of ops an array is unrolled into; longer
+// arrays become an opArray loop.
+const maxUnroll = 32
+
+// encoders caches the *typeEncoder of every type seen.
+var encoders sync.Map // map[reflect.Type]*typeEncoder
+
+// genericCache and ifaceCache are direct-mapped caches in front of encoders,
+// keyed by the type word of *T in the generic functions and by the dynamic
+// type of an interface. A hit costs two loads instead of a map lookup.
+var genericCache, ifaceCache [256]unsafe.Pointer // *typeEncoder
+
+func cacheSlot(cache *[256]unsafe.Pointer, key unsafe.Pointer) *unsafe.Pointer {
+	k := uintptr(key)
+	return &cache[(k>>4^k>>12)%uintptr(len(cache))]
+}
+
+// typeWord returns the type word of x.
+func typeWord(x any) unsafe.Pointer {
+	return (*[2]unsafe.Pointer)(unsafe.Pointer(&x))[0]
+}
+
+// encoderFor returns the encoder of T.
+func encoderFor[T comparable]() *typeEncoder {
+	key := typeWord((*T)(nil))
+	slot := cacheSlot(&genericCache, key)
+	if enc := (*typeEncoder)(atomic.LoadPointer(slot)); enc != nil && atomic.LoadPointer(&enc.ptrType) == key {
+		return enc
+	}
+
+	enc := encoderOf(reflect.TypeOf((*T)(nil)).Elem())
+	atomic.StorePointer(&enc.ptrType, key)
+	atomic.StorePointer(slot, unsafe.Pointer(enc))
+
+	return enc
+}
+
+// dynamicEncoder returns the encoder of the dynamic type of x, which is not nil.
+func dynamicEncoder(x any) *typeEncoder {
+	key := typeWord(x)
+	slot := cacheSlot(&ifaceCache, key)
+	if enc := (*typeEncoder)(atomic.LoadPointer(slot)); enc != nil && enc.rtype == key {
+		return enc
+	}
+
+	enc := encoderOf(reflect.TypeOf(x))
+	atomic.StorePointer(slot, unsafe.Pointer(enc))
+
+	return enc
+}
+
+// encoderOf returns the cached encoder of t, compiling it on first use.
+func encoderOf(t reflect.Type) *typeEncoder {
+	if enc, ok := encoders.Load(t); ok {
+		return enc.(*typeEncoder)
+	}
+	enc, _ := encoders.LoadOrStore(t, newTypeEncoder(t, false))
+
+	return enc.(*typeEncoder)
+}
+
+func newTypeEncoder(t reflect.Type, deep bool) *typeEncoder {
+	enc := &typeEncoder{
+		deep:   deep,
+		direct: directIface(t),
+		rtype:  (*[2]unsafe.Pointer)(unsafe.Pointer(&t))[1],
+		size:   t.Size(),
+	}
+	// An interface is encoded as its dynamic value, from the marker byte.
+	if t.Kind() != reflect.Interface {
+		enc.tag = string(appendTypeName(nil, t))
+	}
+	enc.ops = mergeOps(enc.compile(nil, t, 0))
+	enc.raw = rawEqual(t) && !enc.custom
+
+	n := len(enc.tag)
+	for _, op := range enc.ops {
+		switch {
+		case op.code == opBytes:
+			n += op.n
+		case opWidth[op.code] > 0:
+			n += opWidth[op.code]
+		default:
+			n = smallEncoding + 1
+		}
+	}
+	if n <= smallEncoding && enc.tag != "" {
+		enc.small = n
+	}
+
+	return enc
+}
+
+// compile appends the ops encoding a value of type t at offset off.
+func (enc *typeEncoder) compile(ops []encOp, t reflect.Type, off uintptr) []encOp {
+	if hash := hashableFunc(t); hash != nil {
+		enc.custom = true
+		return append(ops, encOp{code: opHashable, off: off, typ: t, hash: hash})
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return append(ops, encOp{code: op8, off: off})
+	case reflect.Int16, reflect.Uint16:
+		return append(ops, encOp{code: op16, off: off})
+	case reflect.Int32, reflect.Uint32:
+		return append(ops, encOp{code: op32, off: off})
+	case reflect.Int64, reflect.Uint64:
+		return append(ops, encOp{code: op64, off: off})
+	case reflect.Int:
+		return append(ops, encOp{code: opInt, off: off})
+	case reflect.Uint, reflect.Uintptr:
+		return append(ops, encOp{code: opUint, off: off})
+	case reflect.Float32:
+		return append(ops, encOp{code: opFloat32, off: off})
+	case reflect.Float64:
+		return append(ops, encOp{code: opFloat64, off: off})
+	case reflect.Complex64:
+		return append(ops, encOp{code: opComplex64, off: off})
+	case reflect.Complex128:
+		return append(ops, encOp{code: opComplex128, off: off})
+	case reflect.String:
+		return append(ops, encOp{code: opString, off: off})
+	case reflect.Array:
+		if t.Len() == 0 {
+			return ops
+		}
+		if t.Elem().Kind() == reflect.Uint8 && hashableFunc(t.Elem()) == nil {
+			return append(ops, encOp{code: opBytes, off: off, n: t.Len()})
+		}
+		enc.nesting++
+		sub := enc.compile(nil, t.Elem(), 0)
+		enc.nesting--
+		if t.Len()*len(sub) > maxUnroll {
+			return append(ops, encOp{code: opArray, off: off, n: t.Len(), stride: t.Elem().Size(), sub: sub})
+		}
+		for i := 0; i < t.Len(); i++ {
+			for _, op := range sub {
+				op.off += off + uintptr(i)*t.Elem().Size()
+				ops = append(ops, op)
+			}
+		}
+		return ops
+	case reflect.Struct:
+		// The ignorezero fields of the top-level struct go last, see
+		// opIgnoreZero.
+		var last []encOp
+		root := enc.nesting == 0
+		enc.nesting++
+		defer func() { enc.nesting-- }()
+		for i := 0; i < t.NumField(); i++ {
+			// Blank fields are ignored by ==, so they are not encoded.
+			f := t.Field(i)
+			if f.Name == "_" {
+				continue
+			}
+
+			tag, ok := f.Tag.Lookup("rapidhash")
+			if !ok {
+				ops = enc.compile(ops, f.Type, off+f.Offset)
+				continue
+			}
+			enc.custom = true
+			skip, fold, ignoreZero := parseTag(tag)
+			switch {
+			case skip:
+				continue
+			case ignoreZero && root:
+				sub := enc.compileField(nil, f.Type, 0, fold)
+				last = append(last, encOp{code: opIgnoreZero, off: off + f.Offset, n: i, sub: sub})
+			case ignoreZero:
+				sub := enc.compileField(nil, f.Type, 0, fold)
+				ops = append(ops, encOp{code: opPresent, off: off + f.Offset, sub: sub})
+			default:
+				ops = enc.compileField(ops, f.Type, off+f.Offset, fold)
+			}
+		}
+		return append(ops, last...)
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		if enc.deep {
+			return enc.compileDeep(ops, t, off)
+		}
+		if enc.addr == nil {
+			enc.addr = t
+		}
+		return append(ops, encOp{code: opAddr, off: off})
+	case reflect.Slice, reflect.Map:
+		if enc.deep {
+			return enc.compileDeep(ops, t, off)
+		}
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return append(ops, encOp{code: opEface, off: off})
+		}
+		return append(ops, encOp{code: opIface, off: off, typ: t})
+	}
+
+	if enc.bad == nil {
+		enc.bad = t
+	}
+
+	return ops
+}
+
+// compileField is compile for a struct field, whose strings are case-folded
+// if fold is set.
+func (enc *typeEncoder) compileField(ops []encOp, t reflect.Type, off uintptr, fold bool) []encOp {
+	if fold && t.Kind() == reflect.String && hashableFunc(t) == nil {
+		return append(ops, encOp{code: opFoldString, off: off})
+	}
+
+	return enc.compile(ops, t, off)
+}
+
+// parseTag parses the value of a rapidhash struct tag: "-" to skip the
+// field, or a comma-separated list of options, where "fold" hashes a string
+// field with ASCII letters folded to lower case and "ignorezero" leaves the
+// field out of the encoding while it is zero, so that adding a field does
+// not change existing hashes. Unknown options are ignored.
+func parseTag(tag string) (skip, fold, ignoreZero bool) {
+	if tag == "-" {
+		return true, false, false
+	}
+	for tag != "" {
+		var opt string
+		opt, tag, _ = strings.Cut(tag, ",")
+		switch opt {
+		case "fold":
+			fold = true
+		case "ignorezero":
+			ignoreZero = true
+		}
+	}
+
+	return false, fold, ignoreZero
+}
+
+// rawEqual reports whether two values of type t are == exactly when their
+// memory is equal: t is built from booleans and integers only, and has no
+// padding or blank fields, whose bytes == ignores. Floats are excluded for
+// ±0 and NaN, and strings, pointers and interfaces for what they point to.
+func rawEqual(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Array:
+		return t.Len() == 0 || rawEqual(t.Elem())
+	case reflect.Struct:
+		var size uintptr
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Name == "_" || !rawEqual(f.Type) {
+				return false
+			}
+			size += f.Type.Size()
+		}
+		return size == t.Size()
+	}
+
+	return false
+}
+
+// mergeOps turns integer reads into byte copies where memory already holds
+// their encoding, and joins copies of adjacent memory into one.
+func mergeOps(ops []encOp) []encOp {
+	if !nativeLE {
+		return ops
+	}
+
+	out := ops[:0]
+	for _, op := range ops {
+		switch op.code {
+		case op8:
+			op = encOp{code: opBytes, off: op.off, n: 1}
+		case op16:
+			op = encOp{code: opBytes, off: op.off, n: 2}
+		case op32:
+			op = encOp{code: opBytes, off: op.off, n: 4}
+		case op64:
+			op = encOp{code: opBytes, off: op.off, n: 8}
+		case opInt, opUint:
+			if unsafe.Sizeof(uintptr(0)) == 8 {
+				op = encOp{code: opBytes, off: op.off, n: 8}
+			}
+		case opArray, opIgnoreZero, opPresent:
+			op.sub = mergeOps(op.sub)
+		}
+
+		if k := len(out) - 1; k >= 0 && op.code == opBytes && out[k].code == opBytes &&
+			out[k].off+uintptr(out[k].n) == op.off {
+			out[k].n += op.n
+			continue
+		}
+		out = append(out, op)
+	}
+
+	return out
+}
+
+// directIface reports whether a value of type t is stored in the data word
+// of an interface rather than pointed to by it, as for pointer-shaped types.
+func directIface(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Chan, reflect.Map, reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return t.Len() == 1 && directIface(t.Elem())
+	case reflect.Struct:
+		return t.NumField() == 1 && directIface(t.Field(0).Type)
+	}
+
+	return false
+}
+
+// hasherPool holds the Hashers that encodings longer than the stack buffer
+// are streamed into.
+var hasherPool = sync.Pool{
+	New: func() any {
+		return new(Hasher)
+	},
+}
+
+// getHasher returns a Hasher from hasherPool, reset to seed.
+func getHasher(seed uint64) *Hasher {
+	h := hasherPool.Get().(*Hasher)
+	h.variant, h.seed, h.secrets = variantDefault, seed, defaultSecrets
+	h.Reset()
+
+	return h
+}
+
+// encState runs encoders into a fixed buffer. Once it fills up, its
+// contents go to h, to out if set, or nowhere if discard is set, so hashing
+// never allocates.
+type encState struct {
+	buf     [256]byte
+	n       int
+	h       *Hasher
+	pooled  bool // h came from hasherPool
+	out     *[]byte
+	discard bool
+	seed    uint64
+	stable  bool
+	deep    *deepState // set by HashValue
+	nested  bool       // inside another value, see opIgnoreZero
+	err     error
+}
+
+// sum returns the hash of everything written and releases the state.
+func (e *encState) sum() uint64 {
+	if e.h == nil {
+		return HashWithSeed(e.buf[:e.n], e.seed)
+	}
+	e.flush()
+	sum := e.h.Sum64()
+	if e.pooled {
+		hasherPool.Put(e.h)
+	}
+
+	return sum
+}
+
+func (e *encState) flush() {
+	e.emit(e.buf[:e.n])
+	e.n = 0
+}
+
+func (e *encState) emit(b []byte) {
+	switch {
+	case e.discard:
+	case e.out != nil:
+		*e.out = append(*e.out, b...)
+	default:
+		if e.h == nil {
+			e.h = getHasher(e.seed)
+			e.pooled = true
+		}
+		_, _ = e.h.Write(b)
+	}
+}
+
+// writeFolded writes s with ASCII letters folded to lower case.
+func (e *encState) writeFolded(s string) {
+	for len(s) > 0 {
+		if e.n == len(e.buf) {
+			e.flush()
+		}
+		n := copy(e.buf[e.n:], s)
+		for i, c := range e.buf[e.n : e.n+n] {
+			if 'A' <= c && c <= 'Z' {
+				e.buf[e.n+i] = c + 'a' - 'A'
+			}
+		}
+		e.n += n
+		s = s[n:]
+	}
+}
+
+// reserve makes room for n more bytes in buf, flushing it if needed.
+func (e *encState) reserve(n int) {
+	if e.n+n > len(e.buf) {
+		e.flush()
+	}
+}
+
+func (e *encState) write(b []byte) {
+	if e.n+len(b) > len(e.buf) {
+		e.flush()
+		if len(b) > len(e.buf) {
+			e.emit(b)
+			return
+		}
+	}
+	e.n += copy(e.buf[e.n:], b)
+}
+
+func (e *encState) put8(x uint8) {
+	e.buf[e.n] = x
+	e.n++
+}
+
+// value encodes the value of enc's type at p.
+func (e *encState) value(enc *typeEncoder, p unsafe.Pointer) {
+	if enc.bad != nil {
+		e.fail(enc.bad)
+		return
+	}
+	if e.stable && enc.addr != nil {
+		e.fail(enc.addr)
+		return
+	}
+	e.write(stringToBytes(enc.tag))
+	e.run(enc.ops, p)
+}
+
+// any encodes x as held in an interface.
+func (e *encState) any(x any) {
+	if x == nil {
+		e.reserve(1)
+		e.put8(0)
+		return
+	}
+
+	var enc *typeEncoder
+	if e.deep != nil {
+		enc = deepEncoderOf(reflect.TypeOf(x))
+	} else {
+		enc = dynamicEncoder(x)
+	}
+	data := (*[2]unsafe.Pointer)(unsafe.Pointer(&x))[1]
+	p := data
+	if enc.direct {
+		p = unsafe.Pointer(&data)
+	}
+	e.value(enc, noescape(p))
+}
+
+// anyInside is any for an interface inside the value being encoded.
+func (e *encState) anyInside(x any) {
+	nested := e.nested
+	e.nested = true
+	e.any(x)
+	e.nested = nested
+}
+
+// present writes 0 if the value of op's sub at q is zero, or 1 and the
+// value.
+func (e *encState) present(op *encOp, q unsafe.Pointer) {
+	e.reserve(1)
+	if isZero(op.sub, q) {
+		e.put8(0)
+		return
+	}
+	e.put8(1)
+	e.run(op.sub, q)
+}
+
+func (e *encState) fail(t reflect.Type) {
+	if e.err == nil {
+		e.err = &UnsupportedTypeError{Type: t}
+	}
+}
+
+func (e *encState) run(ops []encOp, p unsafe.Pointer) {
+	for i := range ops {
+		op := &ops[i]
+		q := add(p, op.off)
+
+		if opWidth[op.code] > 0 {
+			e.reserve(16)
+			e.n += putFixed(e.buf[e.n:], op.code, q, e.stable)
+			continue
+		}
+
+		switch op.code {
+		case opString:
+			s := *(*string)(q)
+			e.reserve(binary.MaxVarintLen64)
+			e.n += binary.PutUvarint(e.buf[e.n:], uint64(len(s)))
+			e.write(stringToBytes(s))
+		case opBytes:
+			e.write(unsafe.Slice((*byte)(q), op.n))
+		case opArray:
+			for j := 0; j < op.n; j++ {
+				e.run(op.sub, add(q, uintptr(j)*op.stride))
+			}
+		case opEface:
+			e.anyInside(*(*any)(q))
+		case opIface:
+			e.anyInside(reflect.NewAt(op.typ, q).Elem().Interface())
+		case opFoldString:
+			s := *(*string)(q)
+			e.reserve(binary.MaxVarintLen64)
+			e.n += binary.PutUvarint(e.buf[e.n:], uint64(len(s)))
+			e.writeFolded(s)
+		case opIgnoreZero:
+			// The fields of the top-level value come last, so their
+			// indexes cannot be confused with what follows them. Inside
+			// another value they are encoded as opPresent.
+			if e.nested {
+				e.present(op, q)
+			} else if !isZero(op.sub, q) {
+				e.reserve(binary.MaxVarintLen64)
+				e.n += binary.PutUvarint(e.buf[e.n:], uint64(op.n))
+				e.run(op.sub, q)
+			}
+		case opPresent:
+			e.present(op, q)
+		case opHashable:
+			h := getHasher(0)
+			op.hash(h, q)
+			e.reserve(8)
+			binary.LittleEndian.PutUint64(e.buf[e.n:], h.Sum64())
+			e.n += 8
+			hasherPool.Put(h)
+		default:
+			e.runDeep(op, q)
+		}
+	}
+}
+
+// isZero reports whether the value at p, encoded by ops, is == to the zero
+// value of its type, as far as ops encode it.
+func isZero(ops []encOp, p unsafe.Pointer) bool {
+	for i := range ops {
+		op := &ops[i]
+		q := add(p, op.off)
+
+		var zero bool
+		switch op.code {
+		case op8:
+			zero = *(*uint8)(q) == 0
+		case op16:
+			zero = *(*uint16)(q) == 0
+		case op32:
+			zero = *(*uint32)(q) == 0
+		case op64:
+			zero = *(*uint64)(q) == 0
+		case opInt, opUint, opAddr:
+			zero = *(*uintptr)(q) == 0
+		case opFloat32:
+			zero = *(*float32)(q) == 0
+		case opFloat64:
+			zero = *(*float64)(q) == 0
+		case opComplex64:
+			zero = *(*complex64)(q) == 0
+		case opComplex128:
+			zero = *(*complex128)(q) == 0
+		case opString, opFoldString:
+			zero = len(*(*string)(q)) == 0
+		case opBytes:
+			zero = true
+			for _, b := range unsafe.Slice((*byte)(q), op.n) {
+				zero = zero && b == 0
+			}
+		case opArray:
+			zero = true
+			for j := 0; j < op.n && zero; j++ {
+				zero = isZero(op.sub, add(q, uintptr(j)*op.stride))
+			}
+		case opEface, opIface:
+			zero = *(*unsafe.Pointer)(q) == nil
+		case opHashable:
+			zero = reflect.NewAt(op.typ, q).Elem().IsZero()
+		case opPointer:
+			zero = *(*unsafe.Pointer)(q) == nil
+		case opSlice, opByteSlice:
+			zero = len(*(*[]byte)(q)) == 0
+		case opMap:
+			zero = reflect.NewAt(op.typ, q).Elem().Len() == 0
+		case opIgnoreZero, opPresent:
+			zero = isZero(op.sub, q)
+		}
+		if !zero {
+			return false
+		}
+	}
+
+	return true
+}
+
+// putFixed encodes the fixed-width op of the given code at q into b, which
+// has room for 16 bytes, and returns the number of bytes written.
+func putFixed(b []byte, code opCode, q unsafe.Pointer, stable bool) int {
+	_ = b[15]
+	switch code {
+	case op8:
+		b[0] = *(*uint8)(q)
+	case op16:
+		binary.LittleEndian.PutUint16(b, *(*uint16)(q))
+	case op32:
+		binary.LittleEndian.PutUint32(b, *(*uint32)(q))
+	case op64:
+		binary.LittleEndian.PutUint64(b, *(*uint64)(q))
+	case opInt:
+		binary.LittleEndian.PutUint64(b, uint64(*(*int)(q)))
+	case opUint, opAddr:
+		binary.LittleEndian.PutUint64(b, uint64(*(*uintptr)(q)))
+	case opFloat32:
+		binary.LittleEndian.PutUint32(b, float32Bits(*(*float32)(q), stable))
+	case opFloat64:
+		binary.LittleEndian.PutUint64(b, float64Bits(*(*float64)(q), stable))
+	case opComplex64:
+		c := *(*complex64)(q)
+		binary.LittleEndian.PutUint32(b, float32Bits(real(c), stable))
+		binary.LittleEndian.PutUint32(b[4:], float32Bits(imag(c), stable))
+	case opComplex128:
+		c := *(*complex128)(q)
+		binary.LittleEndian.PutUint64(b, float64Bits(real(c), stable))
+		binary.LittleEndian.PutUint64(b[8:], float64Bits(imag(c), stable))
+	}
+
+	return opWidth[code]
+}
+
+// hashSmall hashes the encoding of a value whose encoder has a small
+// fixed length.
+func hashSmall(enc *typeEncoder, p unsafe.Pointer, seed uint64, stable bool) uint64 {
+	var buf [smallEncoding + 16]byte
+	n := copy(buf[:], enc.tag)
+	for i := range enc.ops {
+		op := &enc.ops[i]
+		q := add(p, op.off)
+		if op.code == opBytes {
+			n += copy(buf[n:], unsafe.Slice((*byte)(q), op.n))
+		} else {
+			n += putFixed(buf[n:], op.code, q, stable)
+		}
+	}
+
+	return HashWithSeed(buf[:n], seed)
+}
+
+// hashComparable returns the hash of the [ComparableEncoding] of v.
+func hashComparable[T comparable](v *T, seed uint64, stable bool) (uint64, error) {
+	enc := encoderFor[T]()
+	p := noescape(unsafe.Pointer(v))
+	if enc.raw && !stable {
+		return HashWithSeed(unsafe.Slice((*byte)(p), enc.size), seed), nil
+	}
+	if enc.tag == "" && enc.ops[0].code == opEface {
+		// An empty interface is encoded as its dynamic value, which
+		// may well be small.
+		x := (*[2]unsafe.Pointer)(p)
+		if x[0] != nil {
+			enc = dynamicEncoder(*(*any)(p))
+			if p = unsafe.Pointer(&x[1]); !enc.direct {
+				p = x[1]
+			}
+		}
+	}
+	if enc.small > 0 && enc.bad == nil && (!stable || enc.addr == nil) {
+		return hashSmall(enc, p, seed, stable), nil
+	}
+
+	e := encState{seed: seed, stable: stable}
+	e.value(enc, p)
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	return e.sum(), nil
+}
+
+// appendComparable appends the [ComparableEncoding] of v to buf.
+func appendComparable(buf []byte, v any, stable bool) ([]byte, error) {
+	e := encState{out: &buf, stable: stable}
+	e.any(v)
+	e.flush()
+
+	return buf, e.err
+}
+
+// writeComparable adds the [ComparableEncoding] of v to h. In stable mode v
+// is encoded twice, first to find errors, so nothing is written on error.
+func writeComparable(h *Hasher, v any, stable bool) error {
+	if stable {
+		e := encState{discard: true, stable: true}
+		if e.any(v); e.err != nil {
+			return e.err
+		}
+	}
+
+	e := encState{h: h, stable: stable}
+	e.any(v)
+	if e.err != nil {
+		return e.err
+	}
+	e.flush()
+
+	return nil
+}
+
+// noescape hides p from escape analysis. Encoders only read through the
+// pointers they are given, but the recursion through interfaces would
+// otherwise move every hashed value to the heap.
+//
+//go:nosplit
+func noescape(p unsafe.Pointer) unsafe.Pointer {
+	x := uintptr(p)
+	return *(*unsafe.Pointer)(unsafe.Pointer(&x))
+}
+
+// appendTypeName appends the marker byte of a non-nil value and the
+// length-prefixed name of its type t.
+func appendTypeName(buf []byte, t reflect.Type) []byte {
+	name := typeName(nil, t)
+	buf = append(buf, 1)
+	buf = binary.AppendUvarint(buf, uint64(len(name)))
+
+	return append(buf, name...)
+}
+
+// typeName appends the name of t as it is written in Go, except that named
+// types and unexported field and method names are qualified by their full
+// package path, so that packages with the same name do not collide.
+func typeName(b []byte, t reflect.Type) []byte {
+	if t.Name() != "" {
+		return qualify(b, t.PkgPath(), t.Name())
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(append(b, '*'), t.Elem())
+	case reflect.Array:
+		b = strconv.AppendInt(append(b, '['), int64(t.Len()), 10)
+		return typeName(append(b, ']'), t.Elem())
+	case reflect.Slice:
+		return typeName(append(b, "[]"...), t.Elem())
+	case reflect.Map:
+		b = typeName(append(b, "map["...), t.Key())
+		return typeName(append(b, ']'), t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			b = append(b, "<-chan "...)
+		case reflect.SendDir:
+			b = append(b, "chan<- "...)
+		default:
+			b = append(b, "chan "...)
+		}
+		return typeName(b, t.Elem())
+	case reflect.Func:
+		return signature(append(b, "func"...), t)
+	case reflect.Interface:
+		b = append(b, "interface {"...)
+		for i := 0; i < t.NumMethod(); i++ {
+			m := t.Method(i)
+			b = signature(qualify(append(b, ' '), m.PkgPath, m.Name), m.Type)
+			b = append(b, ';')
+		}
+		return append(b, " }"...)
+	case reflect.Struct:
+		b = append(b, "struct {"...)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			b = append(b, ' ')
+			if !f.Anonymous {
+				b = append(qualify(b, f.PkgPath, f.Name), ' ')
+			}
+			b = typeName(b, f.Type)
+			if f.Tag != "" {
+				b = strconv.AppendQuote(append(b, ' '), string(f.Tag))
+			}
+			b = append(b, ';')
+		}
+		return append(b, " }"...)
+	}
+
+	return append(b, t.String()...)
+}
+
+// qualify appends name, preceded by pkg and a dot if pkg is not empty.
+func qualify(b []byte, pkg, name string) []byte {
+	if pkg != "" {
+		b = append(append(b, pkg...), '.')
+	}
+
+	return append(b, name...)
+}
+
+// signature appends the parameters and results of the function type t.
+func signature(b []byte, t reflect.Type) []byte {
+	b = append(b, '(')
+	for i := 0; i < t.NumIn(); i++ {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		if in := t.In(i); t.IsVariadic() && i == t.NumIn()-1 {
+			b = typeName(append(b, "..."...), in.Elem())
+		} else {
+			b = typeName(b, in)
+		}
+	}
+	b = append(b, ')')
+
+	switch t.NumOut() {
+	case 0:
+		return b
+	case 1:
+		return typeName(append(b, ' '), t.Out(0))
+	}
+	b = append(b, " ("...)
+	for i := 0; i < t.NumOut(); i++ {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = typeName(b, t.Out(i))
+	}
+
+	return append(b, ')')
+}
+
+// unhashable returns the panic value of the non-stable functions for err.
+func unhashable(err error) error {
+	return errors.New("rapidhash: hash of unhashable type " + err.(*UnsupportedTypeError).Type.String())
+}
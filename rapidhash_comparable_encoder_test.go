@@ -0,0 +1,204 @@
+package rapidhash_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"go.dw1.io/rapidhash"
+)
+
+type (
+	fastBool    bool
+	fastInt     int
+	fastInt16   int16
+	fastUint32  uint32
+	fastFloat   float64
+	fastComplex complex64
+	fastString  string
+	fastID      [16]byte
+	fastBytes   [300]byte
+)
+
+// checkFast compares the hashes of v, as a T and as an any, against the
+// reference encoding, or against its memory for types hashed as such.
+func checkFast[T comparable](t *testing.T, v T) {
+	t.Helper()
+
+	for _, seed := range []uint64{0, 0x9e3779b97f4a7c15} {
+		want := rapidhash.HashWithSeed(encodeComparableTest(v), seed)
+		wantT := want
+		if rapidhash.RawComparable[T]() {
+			wantT = rapidhash.HashWithSeed(unsafe.Slice((*byte)(unsafe.Pointer(&v)), unsafe.Sizeof(v)), seed)
+		}
+		if got := rapidhash.HashComparableWithSeed(v, seed); got != wantT {
+			t.Errorf("HashComparableWithSeed(%T(%v), %d) = 0x%x, want 0x%x", v, v, seed, got, wantT)
+		}
+		if got := rapidhash.HashComparableWithSeed(any(v), seed); got != want {
+			t.Errorf("HashComparableWithSeed(any(%T(%v)), %d) = 0x%x, want 0x%x", v, v, seed, got, want)
+		}
+
+		got, err := rapidhash.HashComparableStableWithSeed(v, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("HashComparableStableWithSeed(%T(%v), %d) = 0x%x, want 0x%x", v, v, seed, got, want)
+		}
+	}
+}
+
+func TestHashComparableFastMatchesReference(t *testing.T) {
+	checkFast(t, true)
+	checkFast(t, false)
+	checkFast(t, int(-12345))
+	checkFast(t, int8(-7))
+	checkFast(t, int16(-300))
+	checkFast(t, int32(math.MinInt32))
+	checkFast(t, int64(math.MinInt64))
+	checkFast(t, uint(12345))
+	checkFast(t, uint8(200))
+	checkFast(t, uint16(60000))
+	checkFast(t, uint32(math.MaxUint32))
+	checkFast(t, uint64(0xdeadbeefcafebabe))
+	checkFast(t, uintptr(123456))
+	checkFast(t, float32(-1.5))
+	checkFast(t, float32(math.Copysign(0, -1)))
+	checkFast(t, math.Pi)
+	checkFast(t, math.Copysign(0, -1))
+	checkFast(t, math.Inf(-1))
+	checkFast(t, complex64(complex(1, -2)))
+	checkFast(t, complex(math.Copysign(0, -1), 3))
+	checkFast(t, "")
+	checkFast(t, "rapidhash")
+	checkFast(t, strings.Repeat("x", 1000))
+
+	checkFast(t, fastBool(true))
+	checkFast(t, fastInt(-1))
+	checkFast(t, fastInt16(-2))
+	checkFast(t, fastUint32(3))
+	checkFast(t, fastFloat(4.5))
+	checkFast(t, fastComplex(complex(5, 6)))
+	checkFast(t, fastString("key"))
+	checkFast(t, fastString(strings.Repeat("y", 300)))
+	checkFast(t, fastID{1, 2, 3})
+	checkFast(t, [0]byte{})
+	checkFast(t, [3]uint8{7, 8, 9})
+	checkFast(t, fastBytes{0: 1, 299: 2})
+}
+
+type (
+	encPadded struct {
+		A bool
+		B int64
+		C uint16
+		_ int32
+		D [3]int16
+	}
+	encNested struct {
+		P encPadded
+		S string
+		F float32
+		I any
+		E interface{ Error() string }
+		C complex128
+	}
+	encLong struct {
+		IDs  [40]uint32
+		Tags [2]encPadded
+	}
+	encPtr struct {
+		N int
+		P *int
+		C chan int
+	}
+	encErr string
+)
+
+func (e encErr) Error() string { return string(e) }
+
+func TestHashComparableEncoderStructs(t *testing.T) {
+	checkFast(t, encPadded{A: true, B: -1, C: 7, D: [3]int16{1, -2, 3}})
+	checkFast(t, encNested{})
+	checkFast(t, encNested{
+		P: encPadded{B: 9},
+		S: "field",
+		F: -0.0,
+		I: encPadded{C: 1},
+		E: encErr("bad"),
+		C: complex(1, 2),
+	})
+	checkFast(t, encNested{I: fastString("x"), E: encErr("")})
+	checkFast(t, encLong{IDs: [40]uint32{0: 1, 39: 2}, Tags: [2]encPadded{{A: true}, {C: 3}}})
+	checkFast(t, [2]encNested{{S: "a"}, {S: "b", I: 1}})
+	checkFast(t, [70]fastString{0: "x", 69: "y"})
+
+	x := 1
+	for _, v := range []encPtr{{}, {N: 1, P: &x, C: make(chan int)}} {
+		want := rapidhash.HashWithSeed(encodeComparableTest(v), 5)
+		if got := rapidhash.HashComparableWithSeed(v, 5); got != want {
+			t.Errorf("HashComparableWithSeed(%v) = 0x%x, want 0x%x", v, got, want)
+		}
+		if got := rapidhash.HashComparableWithSeed(any(v), 5); got != want {
+			t.Errorf("HashComparableWithSeed(any(%v)) = 0x%x, want 0x%x", v, got, want)
+		}
+	}
+
+	// A pointer in an interface field is stored in its data word.
+	v := [1]any{&x}
+	want := rapidhash.HashWithSeed(encodeComparableTest(v), 5)
+	if got := rapidhash.HashComparableWithSeed(v, 5); got != want {
+		t.Errorf("HashComparableWithSeed(%v) = 0x%x, want 0x%x", v, got, want)
+	}
+}
+
+func TestHashComparableFastNaN(t *testing.T) {
+	nan1 := math.NaN()
+	nan2 := math.Float64frombits(0xfff0000000000123)
+
+	a, _ := rapidhash.HashComparableStable(nan1)
+	b, _ := rapidhash.HashComparableStable(fastFloat(nan2))
+	c, _ := rapidhash.HashComparableStable(any(fastFloat(nan1)))
+	if d, _ := rapidhash.HashComparableStable(fastFloat(nan1)); b != d || c != d {
+		t.Errorf("stable NaN hashes differ: 0x%x, 0x%x, 0x%x", b, c, d)
+	}
+	if want, _ := rapidhash.HashComparableStable(any(nan2)); a != want {
+		t.Errorf("stable NaN hash 0x%x, reflection gives 0x%x", a, want)
+	}
+
+	f1, _ := rapidhash.HashComparableStable(complex64(complex(float32(nan1), 1)))
+	f2, _ := rapidhash.HashComparableStable(any(complex64(complex(math.Float32frombits(0xff800001), 1))))
+	if f1 != f2 {
+		t.Errorf("stable complex64 NaN hashes differ: 0x%x vs 0x%x", f1, f2)
+	}
+}
+
+func TestHashComparableFastAllocs(t *testing.T) {
+	id := fastID{1, 2, 3}
+	long := strings.Repeat("z", 1000)
+	st := encNested{P: encPadded{B: 1}, S: "s", I: 2}
+	anySt := any(encPadded{B: 3})
+	long2 := encLong{IDs: [40]uint32{1}}
+
+	cases := []struct {
+		name string
+		hash func()
+	}{
+		{"int", func() { sink = rapidhash.HashComparable(42) }},
+		{"string", func() { sink = rapidhash.HashComparable("rapidhash") }},
+		{"long-string", func() { sink = rapidhash.HashComparable(long) }},
+		{"named-string", func() { sink = rapidhash.HashComparable(fastString("key")) }},
+		{"byte-array", func() { sink = rapidhash.HashComparable(id) }},
+		{"complex128", func() { sink = rapidhash.HashComparable(complex(1, 2)) }},
+		{"struct", func() { sink = rapidhash.HashComparable(st) }},
+		{"struct-any", func() { sink = rapidhash.HashComparable(anySt) }},
+		{"long-struct", func() { sink = rapidhash.HashComparable(long2) }},
+	}
+
+	for _, tc := range cases {
+		if allocs := testing.AllocsPerRun(100, tc.hash); allocs != 0 {
+			t.Errorf("%s: %v allocs per run, want 0", tc.name, allocs)
+		}
+	}
+}
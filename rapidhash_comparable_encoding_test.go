@@ -0,0 +1,200 @@
+package rapidhash_test
+
+import (
+	"bytes"
+	"fmt"
+	htemplate "html/template"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	ttemplate "text/template"
+
+	"go.dw1.io/rapidhash"
+)
+
+func TestComparableEncodingAdjacentStrings(t *testing.T) {
+	type pair struct{ A, B string }
+
+	a, b := pair{"ab", ""}, pair{"a", "b"}
+	if rapidhash.HashComparable(a) == rapidhash.HashComparable(b) {
+		t.Errorf("HashComparable(%q) == HashComparable(%q)", a, b)
+	}
+
+	ea, _ := rapidhash.AppendComparableStable(a)
+	eb, _ := rapidhash.AppendComparableStable(b)
+	if bytes.Equal(ea, eb) {
+		t.Errorf("%q and %q both encode to %x", a, b, ea)
+	}
+}
+
+func TestComparableEncodingNilInterface(t *testing.T) {
+	type box struct{ V any }
+
+	values := []any{
+		nil,
+		box{},
+		box{V: struct{}{}},
+		box{V: [0]int{}},
+		box{V: ""},
+		box{V: box{}},
+	}
+
+	seen := map[string]int{}
+	for i, v := range values {
+		enc, err := rapidhash.AppendComparableStable(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j, ok := seen[string(enc)]; ok {
+			t.Errorf("%#v and %#v both encode to %x", values[j], v, enc)
+		}
+		seen[string(enc)] = i
+	}
+}
+
+// TestComparableEncodingInjective hashes random values of randomly built
+// struct types and checks that equal encodings only come from equal values.
+// Values are drawn from small sets so that near-collisions are common.
+func TestComparableEncodingInjective(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	seen := map[string]any{}
+
+	types := 300
+	if testing.Short() {
+		types = 30
+	}
+
+	for i := 0; i < types; i++ {
+		typ := randomStructType(rng, 2)
+		for j := 0; j < 100; j++ {
+			v := randomValue(rng, typ, 2).Interface()
+
+			enc, err := rapidhash.AppendComparableStable(v)
+			if err != nil {
+				t.Fatalf("%T: %v", v, err)
+			}
+			if want := encodeComparableTest(v); !bytes.Equal(enc, want) {
+				t.Fatalf("%#v encodes to %x, reference %x", v, enc, want)
+			}
+
+			if prev, ok := seen[string(enc)]; ok && prev != v {
+				t.Fatalf("%#v and %#v both encode to %x", prev, v, enc)
+			}
+			seen[string(enc)] = v
+		}
+	}
+}
+
+var randomFieldTypes = []reflect.Type{
+	reflect.TypeOf(int8(0)),
+	reflect.TypeOf(uint8(0)),
+	reflect.TypeOf(int16(0)),
+	reflect.TypeOf(uint16(0)),
+	reflect.TypeOf(int32(0)),
+	reflect.TypeOf(uint32(0)),
+	reflect.TypeOf(int64(0)),
+	reflect.TypeOf(uint64(0)),
+	reflect.TypeOf(int(0)),
+	reflect.TypeOf(uint(0)),
+	reflect.TypeOf(uintptr(0)),
+	reflect.TypeOf(false),
+	reflect.TypeOf(float32(0)),
+	reflect.TypeOf(float64(0)),
+	reflect.TypeOf(complex64(0)),
+	reflect.TypeOf(complex128(0)),
+	reflect.TypeOf(""),
+	reflect.TypeOf([0]string{}),
+	reflect.TypeOf([2]string{}),
+	reflect.TypeOf([3]int8{}),
+	reflect.TypeOf(struct{}{}),
+	reflect.TypeOf((*any)(nil)).Elem(),
+}
+
+func randomStructType(rng *rand.Rand, depth int) reflect.Type {
+	fields := make([]reflect.StructField, rng.Intn(4))
+	for i := range fields {
+		typ := randomFieldTypes[rng.Intn(len(randomFieldTypes))]
+		if depth > 0 && rng.Intn(5) == 0 {
+			typ = randomStructType(rng, depth-1)
+		}
+		fields[i] = reflect.StructField{Name: fmt.Sprintf("F%d", i), Type: typ}
+	}
+
+	return reflect.StructOf(fields)
+}
+
+var randomStrings = []string{"", "a", "b", "ab", "ba", "\x00", "\x01a", "a\x00"}
+
+func randomValue(rng *rand.Rand, typ reflect.Type, depth int) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		v.SetInt(int64(rng.Intn(3) - 1))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		v.SetUint(uint64(rng.Intn(3)))
+	case reflect.Bool:
+		v.SetBool(rng.Intn(2) == 1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat([]float64{0, math.Copysign(0, -1), 1, -1.5}[rng.Intn(4)])
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(float64(rng.Intn(2)), math.Copysign(0, float64(rng.Intn(2)-1))))
+	case reflect.String:
+		v.SetString(randomStrings[rng.Intn(len(randomStrings))])
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(randomValue(rng, typ.Elem(), depth))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			v.Field(i).Set(randomValue(rng, typ.Field(i).Type, depth))
+		}
+	case reflect.Interface:
+		if rng.Intn(3) > 0 {
+			dyn := randomFieldTypes[rng.Intn(len(randomFieldTypes)-1)]
+			if depth > 0 && rng.Intn(4) == 0 {
+				dyn = randomStructType(rng, depth-1)
+			}
+			v.Set(randomValue(rng, dyn, depth-1))
+		}
+	}
+
+	return v
+}
+
+func TestComparableEncodingTypeNames(t *testing.T) {
+	type local struct {
+		A int `json:"a"`
+		b string
+		fastID
+	}
+
+	for _, tc := range []struct {
+		v    any
+		want string
+	}{
+		{0, "int"},
+		{stableStruct{}, "go.dw1.io/rapidhash_test.stableStruct"},
+		{(*ttemplate.Template)(nil), "*text/template.Template"},
+		{[2]*htemplate.Template{}, "[2]*html/template.Template"},
+		{local{}, "go.dw1.io/rapidhash_test.local"},
+		{struct {
+			A int `json:"a"`
+			b string
+			fastID
+		}{}, `struct { A int "json:\"a\""; go.dw1.io/rapidhash_test.b string; go.dw1.io/rapidhash_test.fastID; }`},
+		{(chan<- <-chan int)(nil), "chan<- <-chan int"},
+		{(*func(int, ...string) (bool, error))(nil), "*func(int, ...string) (bool, error)"},
+		{(*interface{ M(x int) error })(nil), "*interface { M(int) error; }"},
+	} {
+		if got := rapidhash.TypeName(reflect.TypeOf(tc.v)); got != tc.want {
+			t.Errorf("TypeName(%T) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+
+	// Both print as *template.Template.
+	a, b := any((*ttemplate.Template)(nil)), any((*htemplate.Template)(nil))
+	if rapidhash.HashComparable(a) == rapidhash.HashComparable(b) {
+		t.Errorf("HashComparable(%T) == HashComparable(%T)", a, b)
+	}
+}
@@ -0,0 +1,154 @@
+package rapidhash_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"unsafe"
+
+	"go.dw1.io/rapidhash"
+)
+
+type (
+	rawKey struct {
+		TenantID uint64
+		Shard    uint32
+		Kind     uint32
+	}
+	rawNested struct {
+		K    rawKey
+		IDs  [3]uint16
+		On   bool
+		Mode int8
+		Pad  [8]byte
+	}
+	rawPadded struct {
+		A uint8
+		B uint64
+	}
+	rawTail struct {
+		A uint64
+		B uint8
+	}
+	rawBlank struct {
+		A uint32
+		_ uint32
+	}
+	rawFloat struct {
+		A uint32
+		F float32
+	}
+)
+
+func TestRawComparableTypes(t *testing.T) {
+	cases := []struct {
+		name      string
+		got, want bool
+	}{
+		{"bool", rapidhash.RawComparable[bool](), true},
+		{"int", rapidhash.RawComparable[int](), true},
+		{"uintptr", rapidhash.RawComparable[uintptr](), true},
+		{"fastInt16", rapidhash.RawComparable[fastInt16](), true},
+		{"fastID", rapidhash.RawComparable[fastID](), true},
+		{"[4]uint32", rapidhash.RawComparable[[4]uint32](), true},
+		{"[0]float64", rapidhash.RawComparable[[0]float64](), true},
+		{"struct{}", rapidhash.RawComparable[struct{}](), true},
+		{"rawKey", rapidhash.RawComparable[rawKey](), true},
+		{"rawNested", rapidhash.RawComparable[rawNested](), true},
+		{"[2]rawKey", rapidhash.RawComparable[[2]rawKey](), true},
+
+		{"float64", rapidhash.RawComparable[float64](), false},
+		{"complex64", rapidhash.RawComparable[complex64](), false},
+		{"string", rapidhash.RawComparable[string](), false},
+		{"*int", rapidhash.RawComparable[*int](), false},
+		{"chan int", rapidhash.RawComparable[chan int](), false},
+		{"any", rapidhash.RawComparable[any](), false},
+		{"rawPadded", rapidhash.RawComparable[rawPadded](), false},
+		{"rawTail", rapidhash.RawComparable[rawTail](), false},
+		{"rawBlank", rapidhash.RawComparable[rawBlank](), false},
+		{"rawFloat", rapidhash.RawComparable[rawFloat](), false},
+		{"[2]rawTail", rapidhash.RawComparable[[2]rawTail](), false},
+	}
+
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("RawComparable[%s]() = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestHashComparableRawMatchesHashWithSeed(t *testing.T) {
+	k := rawKey{TenantID: 1 << 40, Shard: 7, Kind: 3}
+	mem := unsafe.Slice((*byte)(unsafe.Pointer(&k)), unsafe.Sizeof(k))
+
+	for _, seed := range []uint64{0, 1, 0x9e3779b97f4a7c15} {
+		if got, want := rapidhash.HashComparableWithSeed(k, seed), rapidhash.HashWithSeed(mem, seed); got != want {
+			t.Errorf("HashComparableWithSeed(%v, %d) = 0x%x, want 0x%x", k, seed, got, want)
+		}
+	}
+
+	// The stable functions keep the type-tagged encoding on every platform.
+	got, err := rapidhash.HashComparableStable(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := rapidhash.Hash(encodeComparableTest(k)); got != want {
+		t.Errorf("HashComparableStable(%v) = 0x%x, want 0x%x", k, got, want)
+	}
+}
+
+// TestHashComparableEqualValues checks that values equal under == hash the
+// same, including values whose memory differs in bytes == ignores.
+func TestHashComparableEqualValues(t *testing.T) {
+	rng := rand.New(rand.NewSource(20))
+	seen := map[rawNested]uint64{}
+	for i := 0; i < 5000; i++ {
+		v := rawNested{
+			K:    rawKey{TenantID: uint64(rng.Intn(4)), Shard: uint32(rng.Intn(3)), Kind: uint32(rng.Intn(2))},
+			IDs:  [3]uint16{uint16(rng.Intn(2)), 0, uint16(rng.Intn(2))},
+			On:   rng.Intn(2) == 0,
+			Mode: int8(rng.Intn(3) - 1),
+		}
+		h := rapidhash.HashComparable(v)
+		if prev, ok := seen[v]; ok && prev != h {
+			t.Fatalf("HashComparable(%v) = 0x%x and 0x%x", v, prev, h)
+		}
+		seen[v] = h
+	}
+
+	a, b := rawPadded{A: 1, B: 2}, rawPadded{A: 1, B: 2}
+	scribble(unsafe.Pointer(&b), unsafe.Offsetof(b.A)+1, unsafe.Offsetof(b.B))
+	checkEqualHash(t, a, b)
+
+	c, d := rawTail{A: 3, B: 4}, rawTail{A: 3, B: 4}
+	scribble(unsafe.Pointer(&d), unsafe.Offsetof(d.B)+1, unsafe.Sizeof(d))
+	checkEqualHash(t, c, d)
+
+	e, f := rawBlank{A: 5}, rawBlank{A: 5}
+	scribble(unsafe.Pointer(&f), 4, 8)
+	checkEqualHash(t, e, f)
+
+	checkEqualHash(t, rawFloat{F: 0}, rawFloat{F: float32(math.Copysign(0, -1))})
+}
+
+// scribble sets the bytes from off to end of the value at p, which the
+// caller makes sure == ignores.
+func scribble(p unsafe.Pointer, off, end uintptr) {
+	for i := off; i < end; i++ {
+		*(*byte)(unsafe.Add(p, i)) = 0xa5
+	}
+}
+
+func checkEqualHash[T comparable](t *testing.T, a, b T) {
+	t.Helper()
+
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+	if ha, hb := rapidhash.HashComparable(a), rapidhash.HashComparable(b); ha != hb {
+		t.Errorf("HashComparable(%T) of equal values = 0x%x and 0x%x", a, ha, hb)
+	}
+	if ha, hb := rapidhash.HashComparable(any(a)), rapidhash.HashComparable(any(b)); ha != hb {
+		t.Errorf("HashComparable(any(%T)) of equal values = 0x%x and 0x%x", a, ha, hb)
+	}
+}
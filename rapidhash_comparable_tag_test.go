@@ -0,0 +1,145 @@
+package rapidhash_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+type (
+	tagSkip struct {
+		ID    uint64
+		Trace string `rapidhash:"-"`
+		Log   *int   `rapidhash:"-"`
+	}
+	tagFold struct {
+		Name  string     `rapidhash:"fold"`
+		Alias fastString `rapidhash:"fold,ignorezero"`
+		Exact string
+	}
+	tagZero struct {
+		N    int        `rapidhash:"ignorezero"`
+		F    float64    `rapidhash:"ignorezero"`
+		S    string     `rapidhash:"ignorezero"`
+		I    any        `rapidhash:"ignorezero"`
+		A    [2]float32 `rapidhash:"ignorezero"`
+		Last uint8
+	}
+	tagZeroPair[T comparable] struct {
+		A, B T `rapidhash:"ignorezero"`
+	}
+	tagZeroInner struct {
+		N int32 `rapidhash:"ignorezero"`
+	}
+	tagZeroInnerS struct {
+		S string `rapidhash:"ignorezero"`
+	}
+	tagZeroOuter struct {
+		A, B tagZeroInner
+	}
+	tagRaw struct {
+		A uint64
+		B uint64 `rapidhash:"-"`
+	}
+	tagUnknown struct {
+		A int    `rapidhash:"bogus"`
+		B string `rapidhash:""`
+	}
+)
+
+func TestComparableTagSkip(t *testing.T) {
+	x := 1
+	checkSameHashes(t, tagSkip{ID: 1, Trace: "a"}, tagSkip{ID: 1, Trace: "b", Log: &x})
+	checkOtherHashes(t, tagSkip{ID: 1}, tagSkip{ID: 2})
+
+	checkSameHashes(t, tagRaw{A: 1, B: 2}, tagRaw{A: 1, B: 3})
+	if rapidhash.RawComparable[tagRaw]() {
+		t.Error("RawComparable[tagRaw]() = true, want false")
+	}
+}
+
+func TestComparableTagFold(t *testing.T) {
+	long := strings.Repeat("MiXeD", 100)
+	checkSameHashes(t, tagFold{Name: "Alice"}, tagFold{Name: "aLICE"})
+	checkSameHashes(t, tagFold{Name: long, Alias: "X"}, tagFold{Name: strings.ToLower(long), Alias: "x"})
+	checkOtherHashes(t, tagFold{Name: "Émile"}, tagFold{Name: "émile"})
+	checkOtherHashes(t, tagFold{Exact: "A"}, tagFold{Exact: "a"})
+	checkOtherHashes(t, tagFold{Name: "a"}, tagFold{Name: "b"})
+}
+
+func TestComparableTagIgnoreZero(t *testing.T) {
+	checkSameHashes(t, tagZero{F: 0}, tagZero{F: math.Copysign(0, -1)})
+	checkSameHashes(t, tagZero{A: [2]float32{float32(math.Copysign(0, -1)), 0}}, tagZero{})
+	checkOtherHashes(t, tagZero{}, tagZero{N: 1})
+	checkOtherHashes(t, tagZero{}, tagZero{S: "s"})
+	checkOtherHashes(t, tagZero{}, tagZero{I: 0})
+	checkOtherHashes(t, tagZero{}, tagZero{A: [2]float32{0, 1}})
+
+	// Each set field is told apart from the others.
+	checkOtherHashes(t, tagZeroPair[string]{"x", ""}, tagZeroPair[string]{"", "x"})
+	checkOtherHashes(t, tagZeroPair[int32]{5, 0}, tagZeroPair[int32]{0, 5})
+	checkOtherHashes(t, tagZero{N: 1}, tagZero{I: 1})
+
+	// So is each struct holding them inside another value.
+	checkOtherHashes(t, tagZeroOuter{A: tagZeroInner{5}}, tagZeroOuter{B: tagZeroInner{5}})
+	checkOtherHashes(t, [2]tagZeroInnerS{{"x"}, {}}, [2]tagZeroInnerS{{}, {"x"}})
+	checkOtherHashes(t, [2]any{tagZeroInnerS{"x"}, tagZeroInnerS{}}, [2]any{tagZeroInnerS{}, tagZeroInnerS{"x"}})
+
+	// A field added with ignorezero leaves the encoding of values that do
+	// not set it unchanged.
+	old, _ := rapidhash.AppendComparableStable(oldKey())
+	cur, _ := rapidhash.AppendComparableStable(newKey(""))
+	if !bytes.Equal(old, cur) {
+		t.Errorf("encoding changed from %x to %x", old, cur)
+	}
+	if set, _ := rapidhash.AppendComparableStable(newKey("x")); bytes.Equal(old, set) {
+		t.Errorf("setting the new field left the encoding at %x", set)
+	}
+}
+
+func oldKey() any {
+	type key struct {
+		ID uint64
+	}
+	return key{ID: 9}
+}
+
+func newKey(region string) any {
+	type key struct {
+		ID     uint64
+		Region string `rapidhash:"ignorezero"`
+	}
+	return key{ID: 9, Region: region}
+}
+
+func TestComparableTagUnknown(t *testing.T) {
+	v := tagUnknown{A: 1, B: "b"}
+	want := rapidhash.HashWithSeed(encodeComparableTest(v), 0)
+	if got := rapidhash.HashComparable(v); got != want {
+		t.Errorf("HashComparable(%v) = 0x%x, want 0x%x", v, got, want)
+	}
+}
+
+func TestComparableTagAllocs(t *testing.T) {
+	skip := tagSkip{ID: 1, Trace: "t"}
+	fold := tagFold{Name: "Name", Alias: "Alias"}
+	zero := tagZero{S: "s"}
+
+	cases := []struct {
+		name string
+		hash func()
+	}{
+		{"skip", func() { sink = rapidhash.HashComparable(skip) }},
+		{"fold", func() { sink = rapidhash.HashComparable(fold) }},
+		{"ignorezero", func() { sink = rapidhash.HashComparable(zero) }},
+	}
+
+	for _, tc := range cases {
+		if allocs := testing.AllocsPerRun(100, tc.hash); allocs != 0 {
+			t.Errorf("%s: %v allocs per run, want 0", tc.name, allocs)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package rapidhash_test
 
 import (
-	"crypto/rand"
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"math"
 	"reflect"
 	"testing"
+	"unsafe"
 
 	"go.dw1.io/rapidhash"
 )
@@ -154,65 +156,78 @@ func hashComparableWithSeedAny(t *testing.T, v any, seed uint64) uint64 {
 	}
 }
 
+// encodeComparableTest is a reference implementation of version 2 of the
+// comparable encoding, for values without NaNs.
 func encodeComparableTest(v any) []byte {
-	return appendValueBytesTest(nil, reflect.ValueOf(v))
+	return appendTaggedTest(nil, reflect.ValueOf(v))
 }
 
-func appendValueBytesTest(buf []byte, v reflect.Value) []byte {
-	buf = append(buf, v.Type().String()...)
+func appendTaggedTest(buf []byte, v reflect.Value) []byte {
+	if !v.IsValid() {
+		return append(buf, 0)
+	}
+	buf = append(buf, 1)
+	name := rapidhash.TypeName(v.Type())
+	buf = binary.AppendUvarint(buf, uint64(len(name)))
+	buf = append(buf, name...)
+
+	return appendValueBytesTest(buf, v)
+}
 
+func appendValueBytesTest(buf []byte, v reflect.Value) []byte {
 	switch v.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool:
+		var b bytes.Buffer
+		_ = binary.Write(&b, binary.LittleEndian, v.Interface())
+		return append(buf, b.Bytes()...)
+	case reflect.Float32:
+		return appendFloatTest(buf, v.Float(), 4)
+	case reflect.Float64:
+		return appendFloatTest(buf, v.Float(), 8)
+	case reflect.Complex64:
+		buf = appendFloatTest(buf, real(v.Complex()), 4)
+		return appendFloatTest(buf, imag(v.Complex()), 4)
+	case reflect.Complex128:
+		buf = appendFloatTest(buf, real(v.Complex()), 8)
+		return appendFloatTest(buf, imag(v.Complex()), 8)
+	case reflect.Int:
 		return appendUint64LETest(buf, uint64(v.Int()))
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	case reflect.Uint, reflect.Uintptr:
 		return appendUint64LETest(buf, v.Uint())
 	case reflect.Array:
-		var tmp [8]byte
 		for i := 0; i < v.Len(); i++ {
-			binary.LittleEndian.PutUint64(tmp[:], uint64(i))
-			buf = append(buf, tmp[:]...)
 			buf = appendValueBytesTest(buf, v.Index(i))
 		}
 		return buf
 	case reflect.String:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
 		return append(buf, v.String()...)
 	case reflect.Struct:
-		var tmp [8]byte
 		for i := 0; i < v.NumField(); i++ {
-			binary.LittleEndian.PutUint64(tmp[:], uint64(i))
-			buf = append(buf, tmp[:]...)
-			buf = appendValueBytesTest(buf, v.Field(i))
+			if v.Type().Field(i).Name != "_" {
+				buf = appendValueBytesTest(buf, v.Field(i))
+			}
 		}
 		return buf
-	case reflect.Complex64, reflect.Complex128:
-		c := v.Complex()
-		buf = appendFloat64BytesTest(buf, real(c))
-		buf = appendFloat64BytesTest(buf, imag(c))
-		return buf
-	case reflect.Float32, reflect.Float64:
-		return appendFloat64BytesTest(buf, v.Float())
-	case reflect.Bool:
-		if v.Bool() {
-			return append(buf, 1)
-		}
-		return append(buf, 0)
 	case reflect.UnsafePointer, reflect.Pointer, reflect.Chan:
 		return appendUint64LETest(buf, uint64(v.Pointer()))
 	case reflect.Interface:
-		return appendValueBytesTest(buf, v.Elem())
+		return appendTaggedTest(buf, v.Elem())
 	}
 
 	return buf
 }
 
-func appendFloat64BytesTest(buf []byte, f float64) []byte {
+func appendFloatTest(buf []byte, f float64, size int) []byte {
 	if f == 0 {
-		return append(buf, 0)
+		f = 0
 	}
-	if math.IsNaN(f) {
-		return appendUint64LETest(buf, randUint64Test())
+	if size == 4 {
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(f)))
 	}
-	return appendUint64LETest(buf, math.Float64bits(f))
+
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f))
 }
 
 func appendUint64LETest(buf []byte, x uint64) []byte {
@@ -221,8 +236,161 @@ func appendUint64LETest(buf []byte, x uint64) []byte {
 	return append(buf, tmp[:]...)
 }
 
-func randUint64Test() uint64 {
-	var tmp [8]byte
-	_, _ = rand.Read(tmp[:])
-	return binary.LittleEndian.Uint64(tmp[:])
+type stableStruct struct {
+	I  int
+	U  uintptr
+	F  float64
+	C  complex64
+	S  string
+	A  [2]int8
+	X  any
+	OK bool
+}
+
+func TestHashComparableStableNaN(t *testing.T) {
+	nan2 := math.Float64frombits(0x7ff8000000000abc)
+
+	for _, v := range []float64{math.NaN(), -math.NaN(), nan2} {
+		got, err := rapidhash.HashComparableStable(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := rapidhash.HashComparableStable(math.NaN())
+		if got != want {
+			t.Errorf("NaN %x: hash 0x%x, want 0x%x", math.Float64bits(v), got, want)
+		}
+	}
+
+	c1, _ := rapidhash.HashComparableStable(complex(math.NaN(), 1))
+	c2, _ := rapidhash.HashComparableStable(complex(nan2, 1))
+	if c1 != c2 {
+		t.Errorf("complex NaN hashes differ: 0x%x vs 0x%x", c1, c2)
+	}
+
+	f1, _ := rapidhash.HashComparableStable(float32(math.NaN()))
+	f2, _ := rapidhash.HashComparableStable(math.Float32frombits(0xffc00123))
+	if f1 != f2 {
+		t.Errorf("float32 NaN hashes differ: 0x%x vs 0x%x", f1, f2)
+	}
+}
+
+func TestHashComparableStableMatchesHashComparable(t *testing.T) {
+	cases := []any{
+		int(-12345),
+		uint64(0xdeadbeefcafebabe),
+		"rapidhash",
+		uintptr(123456),
+		[4]uint32{1, 2, 3, 4},
+		comparableStruct{A: 7, B: "hi", C: [3]uint16{9, 10, 11}},
+		stableStruct{I: -1, F: 1.5, C: 2i, S: "s", X: 3},
+	}
+
+	for _, v := range cases {
+		got, err := rapidhash.HashComparableStableWithSeed(v, 7)
+		if err != nil {
+			t.Fatalf("%T: %v", v, err)
+		}
+		if want := rapidhash.HashComparableWithSeed(v, 7); got != want {
+			t.Errorf("%T: stable hash 0x%x, HashComparable 0x%x", v, got, want)
+		}
+	}
+}
+
+func TestHashComparableStableGolden(t *testing.T) {
+	// Pinned so that any change to ComparableEncoding is noticed; these
+	// values must be the same on every GOARCH.
+	v := stableStruct{
+		I:  -1 << 30,
+		U:  1 << 31,
+		F:  math.NaN(),
+		C:  complex(1, -2),
+		S:  "cache-key",
+		A:  [2]int8{-1, 1},
+		X:  uint16(9),
+		OK: true,
+	}
+
+	cases := []struct {
+		name string
+		got  func() (uint64, error)
+		want uint64
+	}{
+		{"struct", func() (uint64, error) { return rapidhash.HashComparableStable(v) }, 0x3fdda95c1041b4e7},
+		{"struct-seed", func() (uint64, error) { return rapidhash.HashComparableStableWithSeed(v, 42) }, 0xeb583610ee3b10f6},
+		{"nil-any", func() (uint64, error) { return rapidhash.HashComparableStable(stableStruct{}) }, 0x04ed677454a3aed1},
+	}
+
+	for _, tc := range cases {
+		got, err := tc.got()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: hash 0x%x, want 0x%x", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHashComparableStableRejectsAddresses(t *testing.T) {
+	x := 1
+	ch := make(chan int)
+
+	type withPtr struct {
+		A int
+		P *int
+	}
+
+	cases := []struct {
+		name string
+		hash func() (uint64, error)
+		typ  reflect.Type
+	}{
+		{"ptr", func() (uint64, error) { return rapidhash.HashComparableStable(&x) }, reflect.TypeOf(&x)},
+		{"nil-ptr", func() (uint64, error) { return rapidhash.HashComparableStable((*int)(nil)) }, reflect.TypeOf(&x)},
+		{"chan", func() (uint64, error) { return rapidhash.HashComparableStable(ch) }, reflect.TypeOf(ch)},
+		{"unsafe", func() (uint64, error) { return rapidhash.HashComparableStable(unsafe.Pointer(&x)) }, reflect.TypeOf(unsafe.Pointer(nil))},
+		{"field", func() (uint64, error) { return rapidhash.HashComparableStable(withPtr{P: &x}) }, reflect.TypeOf(&x)},
+		{"any", func() (uint64, error) { return rapidhash.HashComparableStable(any(&x)) }, reflect.TypeOf(&x)},
+	}
+
+	for _, tc := range cases {
+		_, err := tc.hash()
+
+		var typeErr *rapidhash.UnsupportedTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("%s: error %v, want *UnsupportedTypeError", tc.name, err)
+		}
+		if typeErr.Type != tc.typ {
+			t.Errorf("%s: error type %v, want %v", tc.name, typeErr.Type, tc.typ)
+		}
+	}
+}
+
+func TestWriteComparableStable(t *testing.T) {
+	v := stableStruct{F: math.NaN(), S: "abc", X: "x"}
+
+	h := rapidhash.NewWithSeed(5)
+	if err := h.WriteComparableStable(v); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := rapidhash.HashComparableStableWithSeed(v, 5)
+	if got := h.Sum64(); got != want {
+		t.Fatalf("WriteComparableStable hash = 0x%x, want 0x%x", got, want)
+	}
+
+	v.F = math.Float64frombits(0xfff0000000000001)
+	h2 := rapidhash.NewWithSeed(5)
+	_ = h2.WriteComparableStable(v)
+	if h2.Sum64() != h.Sum64() {
+		t.Error("WriteComparableStable hashes NaNs differently")
+	}
+
+	x := 1
+	before := h.Sum64()
+	if err := h.WriteComparableStable(&x); err == nil {
+		t.Fatal("WriteComparableStable(pointer) returned nil error")
+	}
+	if h.Sum64() != before {
+		t.Error("WriteComparableStable wrote data for a rejected value")
+	}
 }
@@ -0,0 +1,41 @@
+package rapidhash
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+var errFileChanged = errors.New("rapidhash: file shrank while being hashed")
+
+// HashFile computes [HashWithSeed] of the contents of the named file.
+//
+// On Linux, regular files are memory-mapped and hashed in place, avoiding the
+// copies and system calls of a read loop. Other platforms, pipes, special
+// files and files that cannot be mapped are streamed as with [HashReader].
+// Both paths produce the same hash.
+func HashFile(path string, seed uint64) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return HashOSFile(f, seed)
+}
+
+// HashOSFile computes [HashWithSeed] of the contents of f from its current
+// offset to the end, leaving the offset at the end of the file. It uses the
+// same memory-mapped fast path as [HashFile].
+//
+// A mapped file must not be truncated while it is being hashed; if that
+// happens, HashOSFile returns an error instead of crashing.
+func HashOSFile(f *os.File, seed uint64) (uint64, error) {
+	if sum, ok, err := hashMapped(f, seed); ok {
+		return sum, err
+	}
+
+	sum, _, err := HashReaderContext(context.Background(), f, seed)
+
+	return sum, err
+}
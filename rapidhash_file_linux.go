@@ -0,0 +1,66 @@
+package rapidhash
+
+import (
+	"io"
+	"os"
+	"runtime/debug"
+	"syscall"
+)
+
+// hashMapped hashes f through a read-only memory mapping. It reports
+// ok=false if f cannot be mapped, in which case nothing has been read.
+func hashMapped(f *os.File, seed uint64) (sum uint64, ok bool, err error) {
+	size, off, ok := mappable(f)
+	if !ok {
+		return 0, false, nil
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return 0, false, nil
+	}
+	defer syscall.Munmap(data)
+
+	// Pages past a concurrent truncation raise SIGBUS, which is turned
+	// into an error rather than a crash.
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, fault := r.(interface{ Addr() uintptr }); !fault {
+				panic(r)
+			}
+			sum, err = 0, errFileChanged
+		}
+	}()
+
+	sum = HashWithSeed(data[off:], seed)
+
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return 0, true, err
+	}
+
+	return sum, true, nil
+}
+
+// mappable returns the size of f and its current offset if f is a regular
+// file that is worth mapping.
+func mappable(f *os.File) (size, off int64, ok bool) {
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		return 0, 0, false
+	}
+
+	// Some regular files, such as those in /proc, report a zero size but
+	// still have contents, so they are always streamed.
+	size = fi.Size()
+	if size <= 0 || int64(int(size)) != size {
+		return 0, 0, false
+	}
+
+	off, err = f.Seek(0, io.SeekCurrent)
+	if err != nil || off > size {
+		return 0, 0, false
+	}
+
+	return size, off, true
+}
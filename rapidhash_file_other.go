@@ -0,0 +1,10 @@
+//go:build !linux
+
+package rapidhash
+
+import "os"
+
+// hashMapped reports ok=false, as memory mapping is only used on Linux.
+func hashMapped(f *os.File, seed uint64) (sum uint64, ok bool, err error) {
+	return 0, false, nil
+}
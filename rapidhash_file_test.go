@@ -0,0 +1,137 @@
+package rapidhash_test
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func TestHashFile(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	dir := t.TempDir()
+
+	for _, size := range []int{0, 1, 16, 111, 112, 113, 4095, 4096, 4097, 100003, 1<<20 + 7} {
+		data := make([]byte, size)
+		rng.Read(data)
+
+		path := filepath.Join(dir, "f")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		want := rapidhash.HashWithSeed(data, 5)
+		got, err := rapidhash.HashFile(path, 5)
+		if err != nil {
+			t.Fatalf("size=%d: %v", size, err)
+		}
+		if got != want {
+			t.Errorf("size=%d: HashFile = 0x%x, want 0x%x", size, got, want)
+		}
+
+		// A pipe always takes the streaming path.
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			w.Write(data)
+			w.Close()
+		}()
+		got, err = rapidhash.HashOSFile(r, 5)
+		r.Close()
+		if err != nil {
+			t.Fatalf("size=%d: pipe: %v", size, err)
+		}
+		if got != want {
+			t.Errorf("size=%d: HashOSFile(pipe) = 0x%x, want 0x%x", size, got, want)
+		}
+	}
+}
+
+func TestHashOSFileOffset(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(300, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := rapidhash.HashOSFile(f, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := rapidhash.Hash(data[300:]); got != want {
+		t.Errorf("HashOSFile from offset = 0x%x, want 0x%x", got, want)
+	}
+
+	if off, _ := f.Seek(0, io.SeekCurrent); off != int64(len(data)) {
+		t.Errorf("offset after HashOSFile = %d, want %d", off, len(data))
+	}
+}
+
+func TestHashFileSpecial(t *testing.T) {
+	// Files in /proc report a zero size but have contents.
+	const path = "/proc/self/cmdline"
+	if _, err := os.Stat(path); err != nil {
+		t.Skip("no procfs")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rapidhash.HashFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := rapidhash.Hash(data); got != want {
+		t.Errorf("HashFile(%s) = 0x%x, want 0x%x", path, got, want)
+	}
+}
+
+func TestHashFileMissing(t *testing.T) {
+	if _, err := rapidhash.HashFile(filepath.Join(t.TempDir(), "missing"), 0); !os.IsNotExist(err) {
+		t.Errorf("err = %v, want not-exist error", err)
+	}
+}
+
+func TestHashFileMapped(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory mapping is only used on Linux")
+	}
+
+	data := make([]byte, 5000)
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sum, ok, err := rapidhash.HashMapped(f, 0)
+	if !ok || err != nil {
+		t.Fatalf("regular file was not mapped: ok=%v err=%v", ok, err)
+	}
+	if want := rapidhash.Hash(data); sum != want {
+		t.Errorf("mapped hash = 0x%x, want 0x%x", sum, want)
+	}
+}
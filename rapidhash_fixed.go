@@ -0,0 +1,92 @@
+package rapidhash
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// HashUint32 returns [HashWithSeed] of the 4 little-endian bytes of x.
+//
+// The length is known, so it runs the 4 to 16 byte path of HashWithSeed
+// without building a slice or branching on the length.
+func HashUint32(x uint32, seed uint64) uint64 {
+	return hashFixed(uint64(x), uint64(x), 4, seed)
+}
+
+// HashUint64 returns [HashWithSeed] of the 8 little-endian bytes of x. See
+// [HashUint32].
+func HashUint64(x, seed uint64) uint64 {
+	return hashFixed(x, x, 8, seed)
+}
+
+// HashUint64Pair returns [HashWithSeed] of the 16 little-endian bytes of a
+// followed by b. See [HashUint32].
+func HashUint64Pair(a, b, seed uint64) uint64 {
+	return hashFixed(a, b, 16, seed)
+}
+
+// Hash16 returns [HashWithSeed] of the 16 bytes of k, such as a UUID. See
+// [HashUint32].
+func Hash16(k *[16]byte, seed uint64) uint64 {
+	return hashFixed(u64(unsafe.Pointer(k)), u64(unsafe.Pointer(&k[8])), 16, seed)
+}
+
+// HashUint32s computes [HashUint32] of every key with the given seed,
+// storing the result for keys[i] in out[i]. It panics if out is shorter than
+// keys.
+func HashUint32s(keys []uint32, out []uint64, seed uint64) {
+	if len(out) < len(keys) {
+		panic(errBatchOut)
+	}
+
+	mixed := mixSeed(seed)
+	out = out[:len(keys)]
+	for i, x := range keys {
+		out[i] = hashFixedMixed(uint64(x), uint64(x), 4, mixed)
+	}
+}
+
+// HashUint64s computes [HashUint64] of every key with the given seed,
+// storing the result for keys[i] in out[i]. It panics if out is shorter than
+// keys.
+func HashUint64s(keys, out []uint64, seed uint64) {
+	if len(out) < len(keys) {
+		panic(errBatchOut)
+	}
+
+	mixed := mixSeed(seed)
+	out = out[:len(keys)]
+	for i, x := range keys {
+		out[i] = hashFixedMixed(x, x, 8, mixed)
+	}
+}
+
+// mixSeed returns seed as HashWithSeed mixes it before hashing.
+func mixSeed(seed uint64) uint64 {
+	return seed ^ mix(seed^secret2, secret1)
+}
+
+// hashFixed is the 4 to 16 byte path of [HashWithSeed] for an input of n
+// bytes whose first and last 8 bytes, or 4 if n is less than 8, are a and b.
+//
+// It mixes the seed itself and calls bits.Mul64 directly, rather than going
+// through mixSeed and mum, so that the exported functions stay within the
+// inlining budget, which TestHashFixedInlinable guards.
+func hashFixed(a, b, n, seed uint64) uint64 {
+	hi, lo := bits.Mul64(seed^secret2, secret1)
+	seed ^= lo ^ hi
+	hi, lo = bits.Mul64(a^secret1, b^seed^n)
+	hi, lo = bits.Mul64(lo^secret7, hi^secret1^n)
+
+	return lo ^ hi
+}
+
+// hashFixedMixed is hashFixed for a seed that is already mixed, for the
+// slice functions.
+func hashFixedMixed(a, b, n, seed uint64) uint64 {
+	a ^= secret1
+	b ^= seed ^ n
+	a, b = mum(a, b)
+
+	return mix(a^secret7, b^secret1^n)
+}
@@ -0,0 +1,52 @@
+package rapidhash_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func BenchmarkHashUint64(b *testing.B) {
+	var buf [8]byte
+	b.Run("HashUint64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink = rapidhash.HashUint64(uint64(i), 0)
+		}
+	})
+	b.Run("HashWithSeed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			binary.LittleEndian.PutUint64(buf[:], uint64(i))
+			sink = rapidhash.HashWithSeed(buf[:], 0)
+		}
+	})
+}
+
+func BenchmarkHash16(b *testing.B) {
+	var id [16]byte
+	b.Run("Hash16", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			id[0] = byte(i)
+			sink = rapidhash.Hash16(&id, 0)
+		}
+	})
+	b.Run("HashWithSeed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			id[0] = byte(i)
+			sink = rapidhash.HashWithSeed(id[:], 0)
+		}
+	})
+}
+
+func BenchmarkHashUint64s(b *testing.B) {
+	keys := make([]uint64, 1024)
+	for i := range keys {
+		keys[i] = uint64(i)
+	}
+	out := make([]uint64, len(keys))
+	b.SetBytes(int64(8 * len(keys)))
+
+	for i := 0; i < b.N; i++ {
+		rapidhash.HashUint64s(keys, out, 0)
+	}
+}
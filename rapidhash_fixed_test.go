@@ -0,0 +1,110 @@
+package rapidhash_test
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func TestHashFixedMatchesHashWithSeed(t *testing.T) {
+	rng := rand.New(rand.NewSource(25))
+	values := []uint64{0, 1, 1<<32 - 1, 1 << 63, ^uint64(0)}
+	for i := 0; i < 200; i++ {
+		values = append(values, rng.Uint64())
+	}
+	seeds := []uint64{0, 1, 0x9e3779b97f4a7c15}
+
+	for _, seed := range seeds {
+		for i, x := range values {
+			var b [16]byte
+			binary.LittleEndian.PutUint64(b[:], x)
+			y := values[(i*31+7)%len(values)]
+			binary.LittleEndian.PutUint64(b[8:], y)
+
+			if got, want := rapidhash.HashUint32(uint32(x), seed), rapidhash.HashWithSeed(b[:4], seed); got != want {
+				t.Errorf("HashUint32(0x%x, %d) = 0x%x, want 0x%x", uint32(x), seed, got, want)
+			}
+			if got, want := rapidhash.HashUint64(x, seed), rapidhash.HashWithSeed(b[:8], seed); got != want {
+				t.Errorf("HashUint64(0x%x, %d) = 0x%x, want 0x%x", x, seed, got, want)
+			}
+			if got, want := rapidhash.HashUint64Pair(x, y, seed), rapidhash.HashWithSeed(b[:], seed); got != want {
+				t.Errorf("HashUint64Pair(0x%x, 0x%x, %d) = 0x%x, want 0x%x", x, y, seed, got, want)
+			}
+			if got, want := rapidhash.Hash16(&b, seed), rapidhash.HashWithSeed(b[:], seed); got != want {
+				t.Errorf("Hash16(%x, %d) = 0x%x, want 0x%x", b, seed, got, want)
+			}
+		}
+	}
+
+	if got, want := rapidhash.HashUint64(42, 0), rapidhash.Hash([]byte{42, 0, 0, 0, 0, 0, 0, 0}); got != want {
+		t.Errorf("HashUint64(42, 0) = 0x%x, want Hash = 0x%x", got, want)
+	}
+}
+
+func TestHashFixedSlices(t *testing.T) {
+	keys64 := make([]uint64, 37)
+	keys32 := make([]uint32, 37)
+	for i := range keys64 {
+		keys64[i] = uint64(i) * 0x9e3779b97f4a7c15
+		keys32[i] = uint32(keys64[i] >> 17)
+	}
+
+	out := make([]uint64, len(keys64)+1)
+	rapidhash.HashUint64s(keys64, out, 7)
+	for i, x := range keys64 {
+		if want := rapidhash.HashUint64(x, 7); out[i] != want {
+			t.Errorf("HashUint64s[%d] = 0x%x, want 0x%x", i, out[i], want)
+		}
+	}
+	rapidhash.HashUint32s(keys32, out, 7)
+	for i, x := range keys32 {
+		if want := rapidhash.HashUint32(x, 7); out[i] != want {
+			t.Errorf("HashUint32s[%d] = 0x%x, want 0x%x", i, out[i], want)
+		}
+	}
+	if out[len(keys64)] != 0 {
+		t.Errorf("out[%d] = 0x%x, want it untouched", len(keys64), out[len(keys64)])
+	}
+}
+
+func TestHashFixedSlicesShortOutputPanics(t *testing.T) {
+	for name, f := range map[string]func(){
+		"HashUint64s": func() { rapidhash.HashUint64s(make([]uint64, 5), make([]uint64, 4), 0) },
+		"HashUint32s": func() { rapidhash.HashUint32s(make([]uint32, 5), make([]uint64, 4), 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s with short output did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+// TestHashFixedInlinable checks that the compiler can inline the fixed-size
+// functions, which is most of their advantage over HashWithSeed.
+func TestHashFixedInlinable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the package")
+	}
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	out, err := exec.Command(gotool, "build", "-gcflags=-m", ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build -gcflags=-m: %v\n%s", err, out)
+	}
+	for _, name := range []string{"HashUint32", "HashUint64", "HashUint64Pair", "Hash16"} {
+		if !strings.Contains(string(out), "can inline "+name+"\n") {
+			t.Errorf("%s is not inlinable", name)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package rapidhash
+
+import (
+	"encoding/binary"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// Hashable is implemented by types that control how the comparable functions
+// hash them, such as types whose == compares more than their identity.
+//
+// The comparable functions look for Hashable at every nesting level: the
+// value itself, struct fields, array elements and the dynamic values of
+// interfaces. A Hashable value is encoded as its type name followed by the
+// [Hasher.Sum64] of a new default Hasher that HashTo wrote to, so HashTo need
+// not delimit what it writes. Values that are == must write the same bytes,
+// and HashTo must not keep h.
+//
+// Hashing is also built in for [time.Time], by instant and ignoring the
+// location and monotonic reading; for [net/netip.Addr], by address and zone;
+// and for [math/big.Int], by value. A *big.Int is a pointer and still hashed
+// by address.
+type Hashable interface {
+	HashTo(h *Hasher)
+}
+
+var hashableType = reflect.TypeOf((*Hashable)(nil)).Elem()
+
+// builtinHashables hashes standard types that cannot implement Hashable.
+var builtinHashables = map[reflect.Type]func(h *Hasher, p unsafe.Pointer){
+	reflect.TypeOf(time.Time{}):  hashTime,
+	reflect.TypeOf(netip.Addr{}): hashAddr,
+	reflect.TypeOf(big.Int{}):    hashBigInt,
+}
+
+// hashableFunc returns the function that hashes a value of type t at p, or
+// nil if t is not Hashable.
+func hashableFunc(t reflect.Type) func(h *Hasher, p unsafe.Pointer) {
+	// An interface type, even Hashable itself, is encoded as its dynamic
+	// value, which is checked in turn.
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	if hash, ok := builtinHashables[t]; ok {
+		return hash
+	}
+
+	switch {
+	case t.Implements(hashableType):
+		// The value is passed to HashTo through an interface that points
+		// at it, which only a method with a value receiver can be called
+		// through and which does not outlive the call.
+		typ := (*[2]unsafe.Pointer)(unsafe.Pointer(&t))[1]
+		direct := directIface(t)
+		return func(h *Hasher, p unsafe.Pointer) {
+			var x any
+			w := (*[2]unsafe.Pointer)(unsafe.Pointer(&x))
+			w[0], w[1] = typ, p
+			if direct {
+				w[1] = *(*unsafe.Pointer)(p)
+			}
+			x.(Hashable).HashTo(h)
+		}
+	case reflect.PointerTo(t).Implements(hashableType):
+		// HashTo may keep its pointer receiver, so it gets a copy.
+		return func(h *Hasher, p unsafe.Pointer) {
+			v := reflect.New(t)
+			v.Elem().Set(reflect.NewAt(t, p).Elem())
+			v.Interface().(Hashable).HashTo(h)
+		}
+	}
+
+	return nil
+}
+
+func hashTime(h *Hasher, p unsafe.Pointer) {
+	t := (*time.Time)(p)
+
+	var buf [12]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(t.Unix()))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(t.Nanosecond()))
+	_, _ = h.Write(buf[:])
+}
+
+func hashAddr(h *Hasher, p unsafe.Pointer) {
+	a := (*netip.Addr)(p)
+
+	var buf [1 + 16 + binary.MaxVarintLen64]byte
+	buf[0] = byte(a.BitLen() / 8)
+	b16 := a.As16()
+	copy(buf[1:], b16[:])
+	zone := a.Zone()
+	n := 17 + binary.PutUvarint(buf[17:], uint64(len(zone)))
+	_, _ = h.Write(buf[:n])
+	_, _ = h.WriteString(zone)
+}
+
+// hashBigInt writes the sign of x and the little-endian bytes of its
+// magnitude, the same for 32 and 64-bit words.
+func hashBigInt(h *Hasher, p unsafe.Pointer) {
+	x := (*big.Int)(p)
+
+	var buf [64]byte
+	buf[0] = byte(x.Sign() + 1)
+	size := (x.BitLen() + 7) / 8
+	n := 1 + binary.PutUvarint(buf[1:], uint64(size))
+
+	const wordSize = int(unsafe.Sizeof(big.Word(0)))
+	words := x.Bits()
+	for i := 0; i < size; i++ {
+		if n == len(buf) {
+			_, _ = h.Write(buf[:])
+			n = 0
+		}
+		buf[n] = byte(words[i/wordSize] >> (8 * (i % wordSize)))
+		n++
+	}
+	_, _ = h.Write(buf[:n])
+}
@@ -0,0 +1,227 @@
+package rapidhash_test
+
+import (
+	"encoding/binary"
+	"math/big"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"go.dw1.io/rapidhash"
+)
+
+// foldString hashes case-insensitively.
+type foldString string
+
+func (s foldString) HashTo(h *rapidhash.Hasher) {
+	_, _ = h.WriteString(strings.ToLower(string(s)))
+}
+
+// versioned ignores Rev.
+type versioned struct {
+	ID  uint64
+	Rev uint64
+}
+
+func (v versioned) HashTo(h *rapidhash.Hasher) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v.ID)
+	_, _ = h.Write(b[:])
+}
+
+// foldByte is an ASCII letter that hashes case-insensitively.
+type foldByte byte
+
+func (b foldByte) HashTo(h *rapidhash.Hasher) {
+	if 'A' <= b && b <= 'Z' {
+		b += 'a' - 'A'
+	}
+	_, _ = h.Write([]byte{byte(b)})
+}
+
+// tagSet is a slice, which only hashes through Hashable.
+type tagSet []string
+
+func (t *tagSet) HashTo(h *rapidhash.Hasher) {
+	for _, s := range *t {
+		h.WriteComparable(s)
+	}
+}
+
+type hashableUser struct {
+	Name foldString
+	Age  int
+	Meta any
+}
+
+func comparableHashes[T comparable](t *testing.T, v T) []uint64 {
+	t.Helper()
+
+	stable, err := rapidhash.HashComparableStable(v)
+	if err != nil {
+		t.Fatalf("HashComparableStable(%v): %v", v, err)
+	}
+	h := rapidhash.New()
+	h.WriteComparable(v)
+
+	return []uint64{rapidhash.HashComparable(v), rapidhash.HashComparable(any(v)), stable, h.Sum64()}
+}
+
+func checkSameHashes[T comparable](t *testing.T, a, b T) {
+	t.Helper()
+
+	ha, hb := comparableHashes(t, a), comparableHashes(t, b)
+	for i := range ha {
+		if ha[i] != hb[i] {
+			t.Errorf("hash %d of %v = 0x%x, of %v = 0x%x", i, a, ha[i], b, hb[i])
+		}
+	}
+}
+
+func checkOtherHashes[T comparable](t *testing.T, a, b T) {
+	t.Helper()
+
+	ha, hb := comparableHashes(t, a), comparableHashes(t, b)
+	for i := range ha {
+		if ha[i] == hb[i] {
+			t.Errorf("hash %d of %v and %v = 0x%x", i, a, b, ha[i])
+		}
+	}
+}
+
+func TestHashableNested(t *testing.T) {
+	checkSameHashes(t, foldString("Key"), foldString("KEY"))
+	checkOtherHashes(t, foldString("key"), foldString("kez"))
+
+	checkSameHashes(t, hashableUser{Name: "Ann", Age: 3}, hashableUser{Name: "ann", Age: 3})
+	checkOtherHashes(t, hashableUser{Name: "Ann", Age: 3}, hashableUser{Name: "Ann", Age: 4})
+	checkSameHashes(t,
+		hashableUser{Meta: foldString("X")},
+		hashableUser{Meta: foldString("x")})
+	checkSameHashes(t,
+		[2]hashableUser{{Meta: versioned{ID: 1, Rev: 1}}},
+		[2]hashableUser{{Meta: versioned{ID: 1, Rev: 2}}})
+
+	// The type name still tags the value, as for other types.
+	checkOtherHashes(t, any(foldString("x")), any(string("x")))
+
+	// HashTo overrides the raw-memory path of plain integer structs.
+	checkSameHashes(t, versioned{ID: 7, Rev: 1}, versioned{ID: 7, Rev: 2})
+}
+
+func TestHashableInterfaceType(t *testing.T) {
+	type box struct{ H rapidhash.Hashable }
+
+	checkSameHashes(t, box{foldString("A")}, box{foldString("a")})
+	checkOtherHashes(t, box{foldString("a")}, box{foldString("b")})
+	checkOtherHashes(t, box{foldString("a")}, box{})
+	checkSameHashes[rapidhash.Hashable](t, foldString("A"), foldString("a"))
+	checkOtherHashes[rapidhash.Hashable](t, foldString("a"), versioned{ID: 1})
+}
+
+func TestHashableByteArray(t *testing.T) {
+	// Byte arrays are otherwise copied as memory.
+	checkSameHashes(t, [2]foldByte{'A', 'B'}, [2]foldByte{'a', 'b'})
+	checkOtherHashes(t, [2]foldByte{'a', 'b'}, [2]foldByte{'a', 'c'})
+	if rapidhash.RawComparable[[2]foldByte]() {
+		t.Error("RawComparable[[2]foldByte]() = true, want false")
+	}
+}
+
+func TestHashablePointerReceiver(t *testing.T) {
+	a, b := tagSet{"x", "y"}, tagSet{"x", "y"}
+
+	ha, hb := rapidhash.New(), rapidhash.New()
+	ha.WriteComparable(a)
+	hb.WriteComparable(b)
+	if ha.Sum64() != hb.Sum64() {
+		t.Errorf("WriteComparable(%v) = 0x%x and 0x%x", a, ha.Sum64(), hb.Sum64())
+	}
+
+	hb.Reset()
+	hb.WriteComparable(tagSet{"xy"})
+	if ha.Sum64() == hb.Sum64() {
+		t.Errorf("WriteComparable(%v) == WriteComparable(%v)", a, tagSet{"xy"})
+	}
+}
+
+func TestHashableTime(t *testing.T) {
+	utc := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	tokyo := utc.In(time.FixedZone("JST", 9*60*60))
+	checkSameHashes(t, utc, tokyo)
+	now := time.Now()
+	checkSameHashes(t, now, now.Round(0))
+	checkOtherHashes(t, utc, utc.Add(time.Nanosecond))
+	checkOtherHashes(t, time.Unix(0, 0), time.Unix(1, 0))
+
+	type event struct {
+		At   time.Time
+		Name string
+	}
+	checkSameHashes(t, event{At: utc, Name: "a"}, event{At: tokyo, Name: "a"})
+}
+
+func TestHashableAddr(t *testing.T) {
+	a := netip.MustParseAddr("192.0.2.1")
+	checkSameHashes(t, a, netip.AddrFrom4([4]byte{192, 0, 2, 1}))
+	checkOtherHashes(t, a, netip.MustParseAddr("192.0.2.2"))
+	checkOtherHashes(t, a, netip.MustParseAddr("::ffff:192.0.2.1"))
+	checkOtherHashes(t, netip.MustParseAddr("fe80::1%eth0"), netip.MustParseAddr("fe80::1%eth1"))
+	checkOtherHashes(t, netip.Addr{}, netip.IPv6Unspecified())
+
+	ap := netip.MustParseAddrPort("[2001:db8::1]:443")
+	checkSameHashes(t, ap, netip.AddrPortFrom(netip.MustParseAddr("2001:db8::1"), 443))
+	checkOtherHashes(t, ap, netip.AddrPortFrom(ap.Addr(), 444))
+}
+
+func TestHashableBigInt(t *testing.T) {
+	hash := func(x *big.Int) uint64 {
+		h := rapidhash.New()
+		if err := h.WriteComparableStable(*x); err != nil {
+			t.Fatal(err)
+		}
+		return h.Sum64()
+	}
+
+	a, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	b := new(big.Int).Mul(big.NewInt(1234567890123456789), big.NewInt(100000000000))
+	b.Add(b, big.NewInt(1234567890))
+	if a.Cmp(b) != 0 {
+		t.Fatalf("%v != %v", a, b)
+	}
+	if hash(a) != hash(b) {
+		t.Errorf("equal big.Ints hash to 0x%x and 0x%x", hash(a), hash(b))
+	}
+
+	seen := map[uint64]string{}
+	for _, s := range []string{"0", "1", "-1", "255", "256", "-256", "18446744073709551616"} {
+		x, _ := new(big.Int).SetString(s, 10)
+		h := hash(x)
+		if prev, ok := seen[h]; ok {
+			t.Errorf("big.Ints %s and %s both hash to 0x%x", prev, s, h)
+		}
+		seen[h] = s
+	}
+}
+
+func TestHashableAllocs(t *testing.T) {
+	at := time.Unix(1700000000, 5)
+	addr := netip.MustParseAddr("2001:db8::1")
+	u := hashableUser{Name: "ann", Meta: versioned{ID: 1}}
+
+	cases := []struct {
+		name string
+		hash func()
+	}{
+		{"time", func() { sink = rapidhash.HashComparable(at) }},
+		{"addr", func() { sink = rapidhash.HashComparable(addr) }},
+		{"struct", func() { sink = rapidhash.HashComparable(u) }},
+	}
+
+	for _, tc := range cases {
+		if allocs := testing.AllocsPerRun(100, tc.hash); allocs != 0 {
+			t.Errorf("%s: %v allocs per run, want 0", tc.name, allocs)
+		}
+	}
+}
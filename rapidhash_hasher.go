@@ -1,22 +1,61 @@
 package rapidhash
 
 import (
+	"encoding"
+	"encoding/binary"
+	"errors"
 	"hash"
 	"io"
-	"reflect"
+	"unsafe"
 )
 
 var _ hash.Hash = (*Hasher)(nil)
 var _ hash.Hash64 = (*Hasher)(nil)
 var _ hash.Hash32 = (*Hasher)(nil)
 var _ io.StringWriter = (*Hasher)(nil)
+var _ io.ByteWriter = (*Hasher)(nil)
+var _ encoding.BinaryMarshaler = (*Hasher)(nil)
+var _ encoding.BinaryUnmarshaler = (*Hasher)(nil)
+
+// variant selects which rapidhash variant a [Hasher] computes.
+type variant uint8
+
+const (
+	variantDefault variant = iota
+	variantMicro
+	variantNano
+)
+
+const (
+	// hasherBlock is the largest number of bytes consumed per round, used by
+	// the 7 lanes of the default variant.
+	hasherBlock = 112
+
+	// hasherPrefix is the number of bytes of already-consumed input that are
+	// kept in front of the pending tail. The finalizer reads the last 16 bytes
+	// of the whole input, which may straddle the last consumed block.
+	hasherPrefix = 16
+)
 
 // Hasher implements [hash.Hash32] and [hash.Hash64] for streaming hash computation.
 //
-// Note: For memory-efficiency with large inputs, consider using [Hash] directly.
+// Hasher keeps a constant amount of state regardless of how much data is
+// written: the lane accumulators, the total length, and at most one pending
+// block. The result of [Hasher.Sum64] is identical to the one-shot function
+// of its variant ([HashWithSeed], [HashMicroWithSeed] or [HashNanoWithSeed])
+// over the concatenation of all written data.
+//
+// The zero value is ready to use and equivalent to [New]: a Hasher whose
+// secrets are all zero uses the default secrets.
 type Hasher struct {
-	seed uint64
-	data []byte
+	variant variant
+	seed    uint64
+	secrets Secrets
+	lanes   [7]uint64
+	started bool // lanes are set up, see start
+	total   uint64
+	n       int
+	buf     [hasherPrefix + hasherBlock]byte
 }
 
 // New creates a new Hasher with the default seed (0).
@@ -26,15 +65,70 @@ func New() *Hasher {
 
 // NewWithSeed creates a new Hasher with the given seed.
 func NewWithSeed(seed uint64) *Hasher {
-	return &Hasher{
-		seed: seed,
-		data: make([]byte, 0, 64), // Initial capacity
-	}
+	return newHasher(variantDefault, seed, &defaultSecrets)
+}
+
+// NewMicro creates a new Hasher for the Micro variant with the default seed
+// (0).
+func NewMicro() *Hasher {
+	return NewMicroWithSeed(0)
+}
+
+// NewMicroWithSeed creates a new Hasher for the Micro variant with the given
+// seed.
+func NewMicroWithSeed(seed uint64) *Hasher {
+	return newHasher(variantMicro, seed, &defaultSecrets)
+}
+
+// NewNano creates a new Hasher for the Nano variant with the default seed (0).
+func NewNano() *Hasher {
+	return NewNanoWithSeed(0)
+}
+
+// NewNanoWithSeed creates a new Hasher for the Nano variant with the given
+// seed.
+func NewNanoWithSeed(seed uint64) *Hasher {
+	return newHasher(variantNano, seed, &defaultSecrets)
+}
+
+func newHasher(v variant, seed uint64, secrets *Secrets) *Hasher {
+	h := &Hasher{variant: v, seed: seed, secrets: *secrets}
+	h.Reset()
+
+	return h
+}
+
+// Copy returns an independent copy of the hasher.
+//
+// Copying costs a struct copy, so a shared prefix can be hashed once and then
+// forked for many suffixes. With Go 1.25 and later, [Hasher] also implements
+// hash.Cloner through a Clone method that returns the same copy.
+func (h *Hasher) Copy() *Hasher {
+	c := *h
+
+	return &c
 }
 
 // Reset resets the hasher to its initial state.
 func (h *Hasher) Reset() {
-	h.data = h.data[:0]
+	h.start()
+	h.total = 0
+	h.n = 0
+}
+
+// start sets every lane to the mixed seed, and the default secrets if there
+// are none. The lanes are only read once a block is consumed and the secrets
+// once a block is consumed or a sum taken, so a zero Hasher, which was never
+// Reset, sets them up then.
+func (h *Hasher) start() {
+	if h.secrets == (Secrets{}) {
+		h.secrets = defaultSecrets
+	}
+	mixed := h.seed ^ mix(h.seed^h.secrets[2], h.secrets[1])
+	for i := range h.lanes {
+		h.lanes[i] = mixed
+	}
+	h.started = true
 }
 
 // Size returns the number of bytes Sum will return (8 bytes for a 64-bit hash).
@@ -42,30 +136,281 @@ func (h *Hasher) Size() int {
 	return 8
 }
 
-// BlockSize returns the hash's underlying block size.
+// BlockSize returns the hash's underlying block size: 112 bytes for the
+// default variant, 80 for Micro and 48 for Nano.
 func (h *Hasher) BlockSize() int {
-	return 112
+	switch h.variant {
+	case variantMicro:
+		return 80
+	case variantNano:
+		return 48
+	}
+
+	return hasherBlock
 }
 
 // Write adds more data to the running hash.
 func (h *Hasher) Write(p []byte) (n int, err error) {
-	h.data = append(h.data, p...)
+	n = len(p)
+	h.total += uint64(n)
+	bs := h.BlockSize()
+
+	if h.n+len(p) <= bs {
+		h.n += copy(h.buf[hasherPrefix+h.n:], p)
+
+		return n, nil
+	}
+
+	// A full block is only consumed once more input follows it, since the
+	// last 1..BlockSize bytes of the input always belong to the tail.
+	if h.n > 0 {
+		k := copy(h.buf[hasherPrefix+h.n:hasherPrefix+bs], p)
+		p = p[k:]
+		h.block(unsafe.Pointer(&h.buf[hasherPrefix]))
+		copy(h.buf[:hasherPrefix], h.buf[bs:bs+hasherPrefix])
+		h.n = 0
+	}
+
+	if len(p) > bs {
+		consumed := h.blocks(p)
+		copy(h.buf[:hasherPrefix], p[consumed-hasherPrefix:consumed])
+		p = p[consumed:]
+	}
+
+	h.n = copy(h.buf[hasherPrefix:], p)
+
+	return n, nil
+}
+
+// blocks consumes every block of p that is followed by more input and
+// returns the number of bytes consumed.
+func (h *Hasher) blocks(p []byte) int {
+	if !h.started {
+		h.start()
+	}
+	ptr := unsafe.Pointer(unsafe.SliceData(p))
+	l := &h.lanes
+
+	var rem int
+	switch h.variant {
+	case variantMicro:
+		_, rem, l[0], l[1], l[2], l[3], l[4] = accumBlocksMicro(
+			ptr, len(p), &h.secrets, l[0], l[1], l[2], l[3], l[4])
+	case variantNano:
+		_, rem, l[0], l[1], l[2] = accumBlocksNano(
+			ptr, len(p), &h.secrets, l[0], l[1], l[2])
+	default:
+		_, rem, l[0], l[1], l[2], l[3], l[4], l[5], l[6] = accumBlocks(
+			ptr, len(p), &h.secrets, l[0], l[1], l[2], l[3], l[4], l[5], l[6])
+	}
+
+	return len(p) - rem
+}
 
-	return len(p), nil
+// block mixes a single block into the lanes used by the variant.
+func (h *Hasher) block(p unsafe.Pointer) {
+	if !h.started {
+		h.start()
+	}
+	l, s := &h.lanes, &h.secrets
+	l[0] = mix(u64(p)^s[0], u64(add(p, 8))^l[0])
+	l[1] = mix(u64(add(p, 16))^s[1], u64(add(p, 24))^l[1])
+	l[2] = mix(u64(add(p, 32))^s[2], u64(add(p, 40))^l[2])
+	if h.variant == variantNano {
+		return
+	}
+
+	l[3] = mix(u64(add(p, 48))^s[3], u64(add(p, 56))^l[3])
+	l[4] = mix(u64(add(p, 64))^s[4], u64(add(p, 72))^l[4])
+	if h.variant == variantMicro {
+		return
+	}
+
+	l[5] = mix(u64(add(p, 80))^s[5], u64(add(p, 88))^l[5])
+	l[6] = mix(u64(add(p, 96))^s[6], u64(add(p, 104))^l[6])
 }
 
 // WriteString adds more data to the running hash from a string.
 //
 // This method allows Hasher to implement [io.StringWriter].
 func (h *Hasher) WriteString(s string) (n int, err error) {
-	h.data = append(h.data, s...)
+	return h.Write(stringToBytes(s))
+}
+
+// WriteByte adds the single byte c to the running hash. It always returns
+// nil.
+//
+// This method allows Hasher to implement [io.ByteWriter].
+func (h *Hasher) WriteByte(c byte) error {
+	h.writeUint(uint64(c), 1)
+
+	return nil
+}
+
+// WriteBool adds b to the running hash as one byte, 1 for true and 0 for
+// false.
+func (h *Hasher) WriteBool(b bool) {
+	var x uint64
+	if b {
+		x = 1
+	}
+	h.writeUint(x, 1)
+}
+
+// WriteUint8 adds x to the running hash as one byte.
+func (h *Hasher) WriteUint8(x uint8) {
+	h.writeUint(uint64(x), 1)
+}
+
+// WriteUint16 adds x to the running hash as 2 little-endian bytes.
+func (h *Hasher) WriteUint16(x uint16) {
+	h.writeUint(uint64(x), 2)
+}
+
+// WriteUint32 adds x to the running hash as 4 little-endian bytes.
+func (h *Hasher) WriteUint32(x uint32) {
+	h.writeUint(uint64(x), 4)
+}
+
+// WriteUint64 adds x to the running hash as 8 little-endian bytes.
+func (h *Hasher) WriteUint64(x uint64) {
+	h.writeUint(x, 8)
+}
+
+// WriteInt8 adds x to the running hash as one two's complement byte.
+func (h *Hasher) WriteInt8(x int8) {
+	h.writeUint(uint64(uint8(x)), 1)
+}
+
+// WriteInt16 adds x to the running hash as 2 little-endian two's complement
+// bytes.
+func (h *Hasher) WriteInt16(x int16) {
+	h.writeUint(uint64(uint16(x)), 2)
+}
 
-	return len(s), nil
+// WriteInt32 adds x to the running hash as 4 little-endian two's complement
+// bytes.
+func (h *Hasher) WriteInt32(x int32) {
+	h.writeUint(uint64(uint32(x)), 4)
+}
+
+// WriteInt64 adds x to the running hash as 8 little-endian two's complement
+// bytes.
+func (h *Hasher) WriteInt64(x int64) {
+	h.writeUint(uint64(x), 8)
+}
+
+// WriteFloat64 adds f to the running hash as the 8 little-endian bytes of
+// its IEEE 754 bits, except that -0 is written as +0 and every NaN as
+// 0x7ff8000000000001, the bits of [math.NaN], so values that are == hash the
+// same and so do all NaNs.
+func (h *Hasher) WriteFloat64(f float64) {
+	h.writeUint(float64Bits(f, true), 8)
+}
+
+// writeUint writes the low size bytes of x in little-endian order, like
+// Write but straight into the pending block.
+func (h *Hasher) writeUint(x uint64, size int) {
+	h.total += uint64(size)
+	bs := h.BlockSize()
+
+	if h.n+size <= bs {
+		b := h.buf[hasherPrefix+h.n:]
+		switch size {
+		case 1:
+			b[0] = byte(x)
+		case 2:
+			binary.LittleEndian.PutUint16(b, uint16(x))
+		case 4:
+			binary.LittleEndian.PutUint32(b, uint32(x))
+		default:
+			binary.LittleEndian.PutUint64(b, x)
+		}
+		h.n += size
+
+		return
+	}
+
+	// The bytes straddle the end of the block, which is consumed once the
+	// first byte that follows it is written.
+	for i := 0; i < size; i++ {
+		if h.n == bs {
+			h.block(unsafe.Pointer(&h.buf[hasherPrefix]))
+			copy(h.buf[:hasherPrefix], h.buf[bs:bs+hasherPrefix])
+			h.n = 0
+		}
+		h.buf[hasherPrefix+h.n] = byte(x)
+		x >>= 8
+		h.n++
+	}
 }
 
 // Sum64 returns the current 64-bit hash value.
+//
+// It does not change the underlying hash state, so more data may be written
+// afterwards.
 func (h *Hasher) Sum64() uint64 {
-	return HashWithSeed(h.data, h.seed)
+	if !h.started {
+		h.start()
+	}
+	if h.total <= uint64(h.BlockSize()) {
+		return hashWithSecrets(h.buf[hasherPrefix:hasherPrefix+h.n], h.seed, &h.secrets, h.variant)
+	}
+
+	even, odd := h.fold()
+
+	// The tail is at most one block long, so the checks past the variant's
+	// block size never fire.
+	i := h.n
+	seed := hashTailWithSecrets(unsafe.Pointer(&h.buf[hasherPrefix]), i, even^odd, &h.secrets)
+
+	// The last 16 bytes may reach back into the prefix of the consumed block.
+	a := u64(unsafe.Pointer(&h.buf[hasherPrefix+i-16])) ^ uint64(i)
+	b := u64(unsafe.Pointer(&h.buf[hasherPrefix+i-8]))
+
+	a ^= h.secrets[1]
+	b ^= seed
+	a, b = mum(a, b)
+
+	return mix(a^h.secrets[7], b^h.secrets[1]^uint64(i))
+}
+
+// Sum128 returns the current 128-bit hash value. For hashers created with
+// [New] or [NewWithSeed] it equals [Hash128WithSeed] over all written data;
+// Micro and Nano hashers extend their own variant the same way.
+//
+// Like [Hasher.Sum64], it does not change the underlying hash state.
+func (h *Hasher) Sum128() Uint128 {
+	if !h.started {
+		h.start()
+	}
+	if h.total <= uint64(h.BlockSize()) {
+		return hash128(h.buf[hasherPrefix:hasherPrefix+h.n], h.seed, &h.secrets, h.variant)
+	}
+
+	s := &h.secrets
+	even, odd := h.fold()
+
+	i := h.n
+	seed, seed2 := hashTail128WithSecrets(unsafe.Pointer(&h.buf[hasherPrefix]), i, even^odd, mix(even^s[3], odd^s[4]), s)
+
+	a := u64(unsafe.Pointer(&h.buf[hasherPrefix+i-16])) ^ uint64(i)
+	b := u64(unsafe.Pointer(&h.buf[hasherPrefix+i-8]))
+
+	return finish128(a, b, seed, seed2, i, s)
+}
+
+// fold returns the XOR of the even and of the odd lanes used by the variant.
+func (h *Hasher) fold() (even, odd uint64) {
+	l := &h.lanes
+	switch h.variant {
+	case variantMicro:
+		return l[0] ^ l[2] ^ l[4], l[1] ^ l[3]
+	case variantNano:
+		return l[0] ^ l[2], l[1]
+	}
+
+	return l[0] ^ l[2] ^ l[4] ^ l[6], l[1] ^ l[3] ^ l[5]
 }
 
 // Sum32 returns the lower 32 bits of the current hash value.
@@ -91,12 +436,138 @@ func (h *Hasher) Sum(b []byte) []byte {
 	)
 }
 
+const (
+	// hasherMagic identifies a marshaled [Hasher] state. It is followed by a
+	// format version byte and the variant.
+	hasherMagic = "rh"
+
+	// hasherStateVersion is bumped whenever the marshaled layout changes.
+	// Version 2 added the secrets, version 3 replaced them with their
+	// fingerprint.
+	hasherStateVersion = 3
+
+	// hasherMarshaledSize is the length of a marshaled [Hasher] state: magic,
+	// version, variant, seed, secrets fingerprint, lanes, total length and
+	// the block buffer.
+	hasherMarshaledSize = len(hasherMagic) + 2 + 8 + 8 + 7*8 + 8 + hasherPrefix + hasherBlock
+)
+
+var (
+	errHasherStateID   = errors.New("rapidhash: invalid hash state identifier")
+	errHasherStateSize = errors.New("rapidhash: invalid hash state size")
+	errHasherStateVer  = errors.New("rapidhash: unsupported hash state version")
+	errHasherSecrets   = errors.New("rapidhash: hash state was saved with other secrets")
+)
+
+// MarshalBinary encodes the current state of the hasher, so that it can be
+// restored later with [Hasher.UnmarshalBinary], possibly in another process.
+//
+// The encoding is versioned and starts with a magic prefix. It is
+// independent of the platform byte order.
+//
+// The secrets are not part of the encoding, so that a saved state does not
+// leak custom secrets. Only a fingerprint of them is kept, and the state can
+// only be restored into a Hasher with the same secrets.
+func (h *Hasher) MarshalBinary() ([]byte, error) {
+	return h.AppendBinary(make([]byte, 0, hasherMarshaledSize))
+}
+
+// AppendBinary appends the encoding of [Hasher.MarshalBinary] to b and
+// returns the resulting slice.
+func (h *Hasher) AppendBinary(b []byte) ([]byte, error) {
+	return h.appendBinary(b), nil
+}
+
+func (h *Hasher) appendBinary(b []byte) []byte {
+	if !h.started {
+		h.start()
+	}
+	b = append(b, hasherMagic...)
+	b = append(b, hasherStateVersion, byte(h.variant))
+	b = appendUint64LE(b, h.seed)
+	b = appendUint64LE(b, h.secrets.fingerprint())
+	for _, lane := range h.lanes {
+		b = appendUint64LE(b, lane)
+	}
+	b = appendUint64LE(b, h.total)
+
+	return append(b, h.buf[:]...)
+}
+
+// UnmarshalBinary restores a hasher state previously encoded with
+// [Hasher.MarshalBinary], including its variant and seed. h must already
+// use the secrets of the saved hasher: the zero Hasher and those from [New]
+// and the other package-level constructors for the default secrets, or
+// those from the [Secrets] methods for custom ones. Otherwise it returns an
+// error and leaves h unchanged.
+func (h *Hasher) UnmarshalBinary(b []byte) error {
+	if len(b) < len(hasherMagic)+2 || string(b[:len(hasherMagic)]) != hasherMagic {
+		return errHasherStateID
+	}
+	b = b[len(hasherMagic):]
+
+	if b[0] != hasherStateVersion {
+		return errHasherStateVer
+	}
+
+	v := variant(b[1])
+	if v > variantNano {
+		return errHasherStateID
+	}
+	b = b[2:]
+
+	if len(b) != hasherMarshaledSize-len(hasherMagic)-2 {
+		return errHasherStateSize
+	}
+
+	secrets := &h.secrets
+	if *secrets == (Secrets{}) {
+		secrets = &defaultSecrets
+	}
+	if binary.LittleEndian.Uint64(b[8:]) != secrets.fingerprint() {
+		return errHasherSecrets
+	}
+
+	h.variant = v
+	h.seed = binary.LittleEndian.Uint64(b)
+	b = b[16:]
+	for i := range h.lanes {
+		h.lanes[i] = binary.LittleEndian.Uint64(b)
+		b = b[8:]
+	}
+	h.total = binary.LittleEndian.Uint64(b)
+	b = b[8:]
+	copy(h.buf[:], b)
+
+	// The pending length follows from the total, since a block is only
+	// consumed once more input follows it.
+	bs := uint64(h.BlockSize())
+	// The lanes are only set once a block has been consumed.
+	h.started = h.total > bs
+	if h.total <= bs {
+		h.n = int(h.total)
+	} else {
+		h.n = int((h.total-1)%bs) + 1
+	}
+
+	return nil
+}
+
 // WriteComparable adds a comparable value to the running hash.
 //
 // This is not compatible with [Hash] or [HashWithSeed] because it encodes type
-// information and traverses values via reflection; it also randomizes
+// information along with the value; it also randomizes
 // floating-point NaNs (so results are not deterministic when v contains NaNs)
 // and hashes pointer-like values by address, making results process-specific.
 func (h *Hasher) WriteComparable(v any) {
-	h.data = appendComparableBytes(h.data, reflect.ValueOf(v))
+	if err := writeComparable(h, v, false); err != nil {
+		panic(unhashable(err))
+	}
+}
+
+// WriteComparableStable adds a comparable value to the running hash using
+// the process-stable encoding of [HashComparableStable]. If v cannot be
+// encoded, it returns an [*UnsupportedTypeError] and writes nothing.
+func (h *Hasher) WriteComparableStable(v any) error {
+	return writeComparable(h, v, true)
 }
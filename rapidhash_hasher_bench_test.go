@@ -20,3 +20,16 @@ func BenchmarkHasher1K_Chunked(b *testing.B) {
 		h.Sum64()
 	}
 }
+
+func BenchmarkHasherWriteUint64(b *testing.B) {
+	h := rapidhash.New()
+	b.SetBytes(8 * 16)
+
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		for j := uint64(0); j < 16; j++ {
+			h.WriteUint64(j)
+		}
+		h.Sum64()
+	}
+}
@@ -0,0 +1,19 @@
+//go:build go1.25
+
+package rapidhash
+
+import (
+	"encoding"
+	"hash"
+)
+
+var _ hash.Cloner = (*Hasher)(nil)
+var _ encoding.BinaryAppender = (*Hasher)(nil)
+
+// Clone returns [Hasher.Copy], implementing [hash.Cloner]. The returned
+// value is always a *Hasher and the error is always nil. It is only
+// available with Go 1.25 and later, which added hash.Cloner, so that its
+// signature does not depend on the toolchain; use Copy on older versions.
+func (h *Hasher) Clone() (hash.Cloner, error) {
+	return h.Copy(), nil
+}
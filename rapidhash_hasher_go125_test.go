@@ -0,0 +1,34 @@
+//go:build go1.25
+
+package rapidhash_test
+
+import (
+	"bytes"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func TestHasherClone(t *testing.T) {
+	h := rapidhash.NewMicroWithSeed(9)
+	_, _ = h.Write(bytes.Repeat([]byte("tenant-42/"), 30))
+
+	c, err := h.Clone()
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	fork, ok := c.(*rapidhash.Hasher)
+	if !ok {
+		t.Fatalf("Clone() returned %T, want *rapidhash.Hasher", c)
+	}
+	if fork == h {
+		t.Fatalf("Clone() returned the hasher itself")
+	}
+
+	_, _ = fork.WriteString("orders")
+	want := h.Copy()
+	_, _ = want.WriteString("orders")
+	if got := fork.Sum64(); got != want.Sum64() {
+		t.Errorf("clone Sum64() = 0x%x, want 0x%x", got, want.Sum64())
+	}
+}
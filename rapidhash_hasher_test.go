@@ -3,6 +3,8 @@ package rapidhash_test
 import (
 	"bytes"
 	"hash"
+	"io"
+	"math"
 	"testing"
 
 	"go.dw1.io/rapidhash"
@@ -264,3 +266,409 @@ func TestHasherWriteStringMixed(t *testing.T) {
 			h1.Sum64(), h2.Sum64(), h3.Sum64())
 	}
 }
+
+func TestHasherStreamingMatchesHashWithSeed(t *testing.T) {
+	sizes := []int{0, 1, 16, 17, 111, 112, 113, 127, 128, 129, 223, 224, 225, 336, 448, 449, 1000, 4096, 4097}
+	chunkSizes := []int{1, 3, 16, 100, 111, 112, 113, 224, 500}
+	seeds := []uint64{0, 1, 0xdeadbeef}
+
+	for _, size := range sizes {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*7 + 3)
+		}
+
+		for _, seed := range seeds {
+			expected := rapidhash.HashWithSeed(data, seed)
+
+			for _, chunkSize := range chunkSizes {
+				h := rapidhash.NewWithSeed(seed)
+				for i := 0; i < size; i += chunkSize {
+					end := i + chunkSize
+					if end > size {
+						end = size
+					}
+					_, _ = h.Write(data[i:end])
+				}
+
+				if got := h.Sum64(); got != expected {
+					t.Errorf("size=%d seed=%d chunk=%d: Sum64() = 0x%x, want 0x%x",
+						size, seed, chunkSize, got, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestHasherSumDoesNotChangeState(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	h := rapidhash.New()
+	for i := 0; i < len(data); i += 50 {
+		_, _ = h.Write(data[i : i+50])
+
+		if got, want := h.Sum64(), rapidhash.Hash(data[:i+50]); got != want {
+			t.Fatalf("after %d bytes: Sum64() = 0x%x, want 0x%x", i+50, got, want)
+		}
+	}
+}
+
+func TestHasherIOCopy(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+	h := rapidhash.NewWithSeed(42)
+	n, err := io.Copy(h, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("io.Copy: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("io.Copy copied %d bytes, want %d", n, len(data))
+	}
+
+	if got, want := h.Sum64(), rapidhash.HashWithSeed(data, 42); got != want {
+		t.Errorf("Sum64() = 0x%x, want 0x%x", got, want)
+	}
+}
+
+func TestHasherVariantsMatchOneShot(t *testing.T) {
+	variants := []struct {
+		name    string
+		newFunc func(seed uint64) *rapidhash.Hasher
+		hash    func(data []byte, seed uint64) uint64
+		block   int
+	}{
+		{"Hash", rapidhash.NewWithSeed, rapidhash.HashWithSeed, 112},
+		{"Micro", rapidhash.NewMicroWithSeed, rapidhash.HashMicroWithSeed, 80},
+		{"Nano", rapidhash.NewNanoWithSeed, rapidhash.HashNanoWithSeed, 48},
+	}
+	sizes := []int{0, 3, 16, 17, 47, 48, 49, 79, 80, 81, 96, 97, 160, 161, 500, 1000}
+	chunkSizes := []int{1, 7, 48, 80, 112, 1000}
+
+	for _, v := range variants {
+		for _, size := range sizes {
+			data := make([]byte, size)
+			for i := range data {
+				data[i] = byte(i*13 + 5)
+			}
+
+			for _, seed := range []uint64{0, 12345} {
+				expected := v.hash(data, seed)
+
+				for _, chunkSize := range chunkSizes {
+					h := v.newFunc(seed)
+					for i := 0; i < size; i += chunkSize {
+						end := i + chunkSize
+						if end > size {
+							end = size
+						}
+						_, _ = h.Write(data[i:end])
+					}
+
+					if got := h.Sum64(); got != expected {
+						t.Errorf("%s size=%d seed=%d chunk=%d: Sum64() = 0x%x, want 0x%x",
+							v.name, size, seed, chunkSize, got, expected)
+					}
+				}
+			}
+
+			if got := v.newFunc(0).BlockSize(); got != v.block {
+				t.Errorf("%s: BlockSize() = %d, want %d", v.name, got, v.block)
+			}
+		}
+	}
+}
+
+func TestHasherVariantsDefaultSeed(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog, twice: the quick brown fox jumps over the lazy dog")
+
+	h := rapidhash.NewMicro()
+	_, _ = h.Write(data)
+	if got, want := h.Sum64(), rapidhash.HashMicro(data); got != want {
+		t.Errorf("NewMicro().Sum64() = 0x%x, want 0x%x", got, want)
+	}
+
+	h = rapidhash.NewNano()
+	_, _ = h.Write(data)
+	if got, want := h.Sum64(), rapidhash.HashNano(data); got != want {
+		t.Errorf("NewNano().Sum64() = 0x%x, want 0x%x", got, want)
+	}
+}
+
+func TestHasherMarshalBinaryResume(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i*31 + 1)
+	}
+
+	newFuncs := []struct {
+		name    string
+		newFunc func(seed uint64) *rapidhash.Hasher
+	}{
+		{"Hash", rapidhash.NewWithSeed},
+		{"Micro", rapidhash.NewMicroWithSeed},
+		{"Nano", rapidhash.NewNanoWithSeed},
+	}
+
+	for _, nf := range newFuncs {
+		for _, split := range []int{0, 1, 16, 47, 48, 49, 80, 112, 113, 500, 1000} {
+			h := nf.newFunc(77)
+			_, _ = h.Write(data)
+			expected := h.Sum64()
+
+			h = nf.newFunc(77)
+			_, _ = h.Write(data[:split])
+			state, err := h.MarshalBinary()
+			if err != nil {
+				t.Fatalf("%s split=%d: MarshalBinary: %v", nf.name, split, err)
+			}
+
+			// Restore into a hasher with a different variant and seed; the
+			// state must carry both.
+			resumed := rapidhash.New()
+			if err := resumed.UnmarshalBinary(state); err != nil {
+				t.Fatalf("%s split=%d: UnmarshalBinary: %v", nf.name, split, err)
+			}
+			_, _ = resumed.Write(data[split:])
+
+			if got := resumed.Sum64(); got != expected {
+				t.Errorf("%s split=%d: resumed Sum64() = 0x%x, want 0x%x", nf.name, split, got, expected)
+			}
+		}
+	}
+}
+
+func TestHasherUnmarshalBinaryInvalid(t *testing.T) {
+	h := rapidhash.New()
+	_, _ = h.WriteString("partial input")
+	state, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	badMagic := append([]byte{}, state...)
+	badMagic[0] ^= 0xff
+
+	badVersion := append([]byte{}, state...)
+	badVersion[2]++
+
+	badVariant := append([]byte{}, state...)
+	badVariant[3] = 0xff
+
+	cases := []struct {
+		name  string
+		state []byte
+	}{
+		{"nil", nil},
+		{"magic-only", state[:2]},
+		{"truncated", state[:len(state)-1]},
+		{"trailing", append(append([]byte{}, state...), 0)},
+		{"bad-magic", badMagic},
+		{"bad-version", badVersion},
+		{"bad-variant", badVariant},
+	}
+
+	for _, tc := range cases {
+		if err := rapidhash.New().UnmarshalBinary(tc.state); err == nil {
+			t.Errorf("%s: UnmarshalBinary succeeded, want error", tc.name)
+		}
+	}
+}
+
+func TestHasherAppendBinary(t *testing.T) {
+	h := rapidhash.NewMicroWithSeed(3)
+	_, _ = h.WriteString("some partially hashed input that spans more than one block of data")
+
+	state, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	prefix := []byte("prefix")
+	appended, err := h.AppendBinary(append([]byte{}, prefix...))
+	if err != nil {
+		t.Fatalf("AppendBinary: %v", err)
+	}
+
+	if !bytes.Equal(appended[:len(prefix)], prefix) || !bytes.Equal(appended[len(prefix):], state) {
+		t.Errorf("AppendBinary() = %x, want %x followed by %x", appended, prefix, state)
+	}
+}
+
+func TestHasherTypedWrites(t *testing.T) {
+	variants := []struct {
+		name    string
+		newFunc func(seed uint64) *rapidhash.Hasher
+		hash    func(data []byte, seed uint64) uint64
+	}{
+		{"Hash", rapidhash.NewWithSeed, rapidhash.HashWithSeed},
+		{"Micro", rapidhash.NewMicroWithSeed, rapidhash.HashMicroWithSeed},
+		{"Nano", rapidhash.NewNanoWithSeed, rapidhash.HashNanoWithSeed},
+	}
+
+	// Each write with the bytes it stands for.
+	writes := []struct {
+		write func(h *rapidhash.Hasher)
+		bytes []byte
+	}{
+		{func(h *rapidhash.Hasher) { _ = h.WriteByte(0xab) }, []byte{0xab}},
+		{func(h *rapidhash.Hasher) { h.WriteBool(true) }, []byte{1}},
+		{func(h *rapidhash.Hasher) { h.WriteBool(false) }, []byte{0}},
+		{func(h *rapidhash.Hasher) { h.WriteUint8(0xcd) }, []byte{0xcd}},
+		{func(h *rapidhash.Hasher) { h.WriteUint16(0x0102) }, []byte{2, 1}},
+		{func(h *rapidhash.Hasher) { h.WriteUint32(0x01020304) }, []byte{4, 3, 2, 1}},
+		{func(h *rapidhash.Hasher) { h.WriteUint64(0x0102030405060708) }, []byte{8, 7, 6, 5, 4, 3, 2, 1}},
+		{func(h *rapidhash.Hasher) { h.WriteInt8(-2) }, []byte{0xfe}},
+		{func(h *rapidhash.Hasher) { h.WriteInt16(-2) }, []byte{0xfe, 0xff}},
+		{func(h *rapidhash.Hasher) { h.WriteInt32(-2) }, []byte{0xfe, 0xff, 0xff, 0xff}},
+		{func(h *rapidhash.Hasher) { h.WriteInt64(-2) }, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{func(h *rapidhash.Hasher) { h.WriteFloat64(1.5) }, []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}},
+		{func(h *rapidhash.Hasher) { h.WriteFloat64(math.Copysign(0, -1)) }, make([]byte, 8)},
+		{func(h *rapidhash.Hasher) { h.WriteFloat64(math.NaN()) }, []byte{1, 0, 0, 0, 0, 0, 0xf8, 0x7f}},
+		{func(h *rapidhash.Hasher) { h.WriteFloat64(math.Float64frombits(0xfff0000000000123)) }, []byte{1, 0, 0, 0, 0, 0, 0xf8, 0x7f}},
+	}
+
+	for _, v := range variants {
+		for seed := uint64(0); seed < 2; seed++ {
+			// Cycle through the writes long enough to cross several blocks
+			// at every offset.
+			h := v.newFunc(seed)
+			var data []byte
+			for i := 0; i < 400; i++ {
+				w := writes[(i*7)%len(writes)]
+				w.write(h)
+				data = append(data, w.bytes...)
+
+				if got, want := h.Sum64(), v.hash(data, seed); got != want {
+					t.Fatalf("%s seed=%d after %d writes: Sum64() = 0x%x, want 0x%x", v.name, seed, i+1, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestHasherTypedWritesAllocs(t *testing.T) {
+	h := rapidhash.New()
+	allocs := testing.AllocsPerRun(100, func() {
+		h.WriteUint64(1)
+		h.WriteUint32(2)
+		h.WriteFloat64(3)
+		_ = h.WriteByte(4)
+	})
+	if allocs != 0 {
+		t.Errorf("typed writes: %v allocs per run, want 0", allocs)
+	}
+}
+
+// TestHasherZeroValueLanes checks that a Hasher that was never Reset sets up
+// its lanes from the seed before consuming the first block.
+func TestHasherZeroValueLanes(t *testing.T) {
+	data := make([]byte, 500)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	var zero rapidhash.Secrets
+	for _, chunk := range []int{1, 8, 100, 113, 500} {
+		var h rapidhash.Hasher
+		want := zero.New()
+		for i := 0; i < len(data); i += chunk {
+			end := i + chunk
+			if end > len(data) {
+				end = len(data)
+			}
+			_, _ = h.Write(data[i:end])
+			_, _ = want.Write(data[i:end])
+		}
+		if got, want := h.Sum64(), want.Sum64(); got != want {
+			t.Errorf("chunk=%d: zero Hasher Sum64() = 0x%x, want 0x%x", chunk, got, want)
+		}
+	}
+
+	var h rapidhash.Hasher
+	if _, err := h.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	want := zero.New()
+	_, _ = want.Write(data)
+	if got, want := h.Sum128(), want.Sum128(); got != want {
+		t.Errorf("zero Hasher ReadFrom Sum128() = %v, want %v", got, want)
+	}
+
+	// A marshaled state from before the first block restores the same way.
+	var short rapidhash.Hasher
+	_, _ = short.Write(data[:50])
+	state, _ := short.MarshalBinary()
+	restored := rapidhash.New()
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = restored.Write(data[50:])
+	if got, want := restored.Sum64(), want.Sum64(); got != want {
+		t.Errorf("restored zero Hasher Sum64() = 0x%x, want 0x%x", got, want)
+	}
+}
+
+func TestHasherZeroValue(t *testing.T) {
+	for _, size := range []int{0, 3, 11, 16, 17, 112, 113, 500} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*13 + 1)
+		}
+
+		var h rapidhash.Hasher
+		_, _ = h.Write(data)
+		if got, want := h.Sum64(), rapidhash.Hash(data); got != want {
+			t.Errorf("size=%d: zero Hasher Sum64() = 0x%x, want 0x%x", size, got, want)
+		}
+		if got, want := h.Sum128(), rapidhash.Hash128(data); got != want {
+			t.Errorf("size=%d: zero Hasher Sum128() = %v, want %v", size, got, want)
+		}
+
+		var r rapidhash.Hasher
+		r.Reset()
+		_, _ = r.Write(data)
+		if got, want := r.Sum64(), rapidhash.Hash(data); got != want {
+			t.Errorf("size=%d: Reset zero Hasher Sum64() = 0x%x, want 0x%x", size, got, want)
+		}
+	}
+
+	var h rapidhash.Hasher
+	state, _ := h.MarshalBinary()
+	want, _ := rapidhash.New().MarshalBinary()
+	if !bytes.Equal(state, want) {
+		t.Errorf("zero Hasher MarshalBinary() differs from New()")
+	}
+}
+
+func TestHasherCopy(t *testing.T) {
+	prefix := bytes.Repeat([]byte("tenant-42/"), 30)
+	suffixes := []string{"", "a", "orders", string(make([]byte, 300))}
+
+	for _, newFunc := range []func(uint64) *rapidhash.Hasher{
+		rapidhash.NewWithSeed, rapidhash.NewMicroWithSeed, rapidhash.NewNanoWithSeed,
+	} {
+		base := newFunc(9)
+		_, _ = base.Write(prefix)
+		baseSum := base.Sum64()
+
+		for _, suffix := range suffixes {
+			fork := base.Copy()
+			_, _ = fork.WriteString(suffix)
+
+			want := newFunc(9)
+			_, _ = want.Write(prefix)
+			_, _ = want.WriteString(suffix)
+
+			if got := fork.Sum64(); got != want.Sum64() {
+				t.Errorf("suffix len=%d: fork Sum64() = 0x%x, want 0x%x", len(suffix), got, want.Sum64())
+			}
+		}
+
+		if got := base.Sum64(); got != baseSum {
+			t.Errorf("writing to copies changed the original: 0x%x, want 0x%x", got, baseSum)
+		}
+	}
+}
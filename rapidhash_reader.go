@@ -0,0 +1,103 @@
+package rapidhash
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+var _ io.ReaderFrom = (*Hasher)(nil)
+
+var errReaderCount = errors.New("rapidhash: reader returned invalid count")
+
+// readerBuffer is the size of the buffer [Hasher.ReadFrom] reads into. It is
+// a multiple of every variant's block size, so whole blocks are hashed in
+// place straight from the buffer.
+const readerBuffer = 20 * 1680
+
+var readerPool = sync.Pool{
+	New: func() any {
+		return new([readerBuffer]byte)
+	},
+}
+
+// HashReader computes [HashWithSeed] of everything read from r until EOF,
+// without buffering the whole input. It returns the hash, the number of bytes
+// read, and the first read error other than [io.EOF].
+func HashReader(r io.Reader, seed uint64) (uint64, int64, error) {
+	return HashReaderContext(context.Background(), r, seed)
+}
+
+// HashReaderContext is like [HashReader] but stops reading once ctx is done,
+// returning ctx.Err(). The context is checked between reads, so a Read that
+// blocks is not interrupted.
+func HashReaderContext(ctx context.Context, r io.Reader, seed uint64) (uint64, int64, error) {
+	h := NewWithSeed(seed)
+	n, err := h.readFrom(ctx, r)
+
+	return h.Sum64(), n, err
+}
+
+// ReadFrom reads data from r until EOF and adds it to the running hash. It
+// returns the number of bytes read and any error other than [io.EOF].
+//
+// Data is read into a block-aligned buffer and hashed from there, so it is
+// never copied into the Hasher except for the final partial block. This
+// method allows [io.Copy] to use Hasher without an intermediate buffer.
+func (h *Hasher) ReadFrom(r io.Reader) (int64, error) {
+	return h.readFrom(context.Background(), r)
+}
+
+func (h *Hasher) readFrom(ctx context.Context, r io.Reader) (int64, error) {
+	bufp := readerPool.Get().(*[readerBuffer]byte)
+	defer readerPool.Put(bufp)
+
+	buf := bufp[:]
+	bs := h.BlockSize()
+
+	// The pending tail moves to the front of the buffer, so blocks that
+	// start in it are consumed in the same pass as the data read after it.
+	m := copy(buf, h.buf[hasherPrefix:hasherPrefix+h.n])
+
+	var total int64
+	var err error
+	for {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		var k int
+		k, err = r.Read(buf[m:])
+		if k < 0 || k > len(buf)-m {
+			err = errReaderCount
+			k = 0
+		}
+		m += k
+		total += int64(k)
+		h.total += uint64(k)
+
+		if m == len(buf) {
+			consumed := h.blocks(buf[:m])
+			copy(h.buf[:hasherPrefix], buf[consumed-hasherPrefix:consumed])
+			m = copy(buf, buf[consumed:m])
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if m > bs {
+		consumed := h.blocks(buf[:m])
+		copy(h.buf[:hasherPrefix], buf[consumed-hasherPrefix:consumed])
+		m = copy(buf, buf[consumed:m])
+	}
+	h.n = copy(h.buf[hasherPrefix:], buf[:m])
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	return total, err
+}
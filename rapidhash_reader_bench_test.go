@@ -0,0 +1,35 @@
+package rapidhash_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func BenchmarkHashReader(b *testing.B) {
+	data := make([]byte, 1<<20)
+
+	// Hide bytes.Reader.WriteTo and Hasher.ReadFrom so that both cases read
+	// through an io.Reader like a file or socket would.
+	type reader struct{ io.Reader }
+	type writer struct{ io.Writer }
+
+	b.Run("HashReader", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			rapidhash.HashReader(reader{bytes.NewReader(data)}, 0)
+		}
+	})
+
+	b.Run("CopyBuffer", func(b *testing.B) {
+		buf := make([]byte, 32<<10)
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			h := rapidhash.New()
+			io.CopyBuffer(writer{h}, reader{bytes.NewReader(data)}, buf)
+			h.Sum64()
+		}
+	})
+}
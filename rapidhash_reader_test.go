@@ -0,0 +1,118 @@
+package rapidhash_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+
+	"go.dw1.io/rapidhash"
+)
+
+func TestHashReader(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	data := make([]byte, 200000)
+	rng.Read(data)
+
+	sizes := []int{0, 1, 16, 17, 112, 113, 224, 225, 33599, 33600, 33601, 67200, 100000, len(data)}
+	for _, size := range sizes {
+		want := rapidhash.HashWithSeed(data[:size], 99)
+
+		readers := map[string]io.Reader{
+			"plain":   bytes.NewReader(data[:size]),
+			"onebyte": iotest.OneByteReader(bytes.NewReader(data[:size])),
+			"half":    iotest.HalfReader(bytes.NewReader(data[:size])),
+			"dataerr": iotest.DataErrReader(bytes.NewReader(data[:size])),
+		}
+		for name, r := range readers {
+			if size > 5000 && name == "onebyte" {
+				continue
+			}
+			got, n, err := rapidhash.HashReader(r, 99)
+			if err != nil {
+				t.Fatalf("%s size=%d: unexpected error %v", name, size, err)
+			}
+			if n != int64(size) {
+				t.Errorf("%s size=%d: read %d bytes", name, size, n)
+			}
+			if got != want {
+				t.Errorf("%s size=%d: got 0x%x, want 0x%x", name, size, got, want)
+			}
+		}
+	}
+}
+
+func TestHasherReadFromMixedWithWrite(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	data := make([]byte, 100000)
+	rng.Read(data)
+
+	for _, h := range []struct {
+		name string
+		new  func() *rapidhash.Hasher
+		hash func([]byte) uint64
+	}{
+		{"Default", rapidhash.New, rapidhash.Hash},
+		{"Micro", rapidhash.NewMicro, rapidhash.HashMicro},
+		{"Nano", rapidhash.NewNano, rapidhash.HashNano},
+	} {
+		for _, split := range []int{0, 5, 48, 80, 112, 1000} {
+			hasher := h.new()
+			hasher.Write(data[:split])
+			if _, err := hasher.ReadFrom(bytes.NewReader(data[split : len(data)-split])); err != nil {
+				t.Fatal(err)
+			}
+			hasher.Write(data[len(data)-split:])
+
+			if got, want := hasher.Sum64(), h.hash(data); got != want {
+				t.Errorf("%s split=%d: got 0x%x, want 0x%x", h.name, split, got, want)
+			}
+		}
+	}
+}
+
+func TestHashReaderError(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(bytes.NewReader(make([]byte, 1000)), iotest.ErrReader(boom))
+
+	_, n, err := rapidhash.HashReader(r, 0)
+	if err != boom {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+	if n != 1000 {
+		t.Errorf("n = %d, want 1000", n)
+	}
+}
+
+type cancelReader struct {
+	cancel context.CancelFunc
+	after  int
+	reads  int
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	r.reads++
+	if r.reads == r.after {
+		r.cancel()
+	}
+	for i := range p {
+		p[i] = byte(i)
+	}
+	return len(p), nil
+}
+
+func TestHashReaderContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &cancelReader{cancel: cancel, after: 3}
+
+	_, n, err := rapidhash.HashReaderContext(ctx, r, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if r.reads != 3 || n == 0 {
+		t.Errorf("reads = %d, n = %d; want reading to stop right after cancellation", r.reads, n)
+	}
+}
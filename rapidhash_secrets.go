@@ -0,0 +1,269 @@
+package rapidhash
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"unsafe"
+)
+
+// Secrets is a set of the 8 secret constants that parameterize rapidhash,
+// equivalent to the secret array accepted by the C reference's internal
+// functions.
+//
+// Hashing with per-deployment secrets means an attacker who only knows the
+// default constants cannot precompute colliding inputs. Use [MakeSecrets] to
+// derive a well-formed set; arbitrary values may degrade hash quality. The
+// zero Secrets is not a valid set: MakeSecrets never returns it, and a
+// [Hasher] given it uses the default secrets instead.
+type Secrets [8]uint64
+
+// defaultSecrets are the secrets used by [Hash] and the other package-level
+// functions.
+var defaultSecrets = Secrets{secret0, secret1, secret2, secret3, secret4, secret5, secret6, secret7}
+
+// secretBytes are the byte values with exactly 4 of 8 bits set, from which
+// [MakeSecrets] assembles each secret.
+var secretBytes = [...]byte{
+	15, 23, 27, 29, 30, 39, 43, 45, 46, 51, 53, 54, 57, 58, 60, 71,
+	75, 77, 78, 83, 85, 86, 89, 90, 92, 99, 101, 102, 105, 106, 108, 113,
+	114, 116, 120, 135, 139, 141, 142, 147, 149, 150, 153, 154, 156, 163, 165, 166,
+	169, 170, 172, 177, 178, 180, 184, 195, 197, 198, 201, 202, 204, 209,
+	210, 212, 216, 225, 226, 228, 232, 240,
+}
+
+// MakeSecrets derives a well-formed set of secrets from seed.
+//
+// It follows make_secret from the wyhash/rapidhash C reference, extended to
+// all 8 secrets: every secret is odd, each of its bytes has exactly 4 bits
+// set, and every pair of secrets differs in exactly 32 bits. The search takes
+// on the order of milliseconds, so derive secrets once and reuse them.
+func MakeSecrets(seed uint64) Secrets {
+	var s Secrets
+	for i := range s {
+		for {
+			s[i] = 0
+			for j := 0; j < 64; j += 8 {
+				s[i] |= uint64(secretBytes[wyrand(&seed)%uint64(len(secretBytes))]) << j
+			}
+			if s[i]&1 == 0 {
+				continue
+			}
+
+			ok := true
+			for j := 0; j < i; j++ {
+				if bits.OnesCount64(s[j]^s[i]) != 32 {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				break
+			}
+		}
+	}
+
+	return s
+}
+
+// wyrand is the wyhash pseudo-random generator used by [MakeSecrets].
+func wyrand(seed *uint64) uint64 {
+	*seed += secret0
+
+	return mix(*seed, *seed^secret1)
+}
+
+// fingerprint identifies s in a marshaled [Hasher] state without revealing
+// it.
+func (s *Secrets) fingerprint() uint64 {
+	var b [len(s) * 8]byte
+	for i, secret := range s {
+		binary.LittleEndian.PutUint64(b[i*8:], secret)
+	}
+
+	return HashWithSeed(b[:], 0)
+}
+
+// HashWithSecrets computes a 64-bit rapidhash of data using seed and the
+// given secrets. With the default secrets it is identical to [HashWithSeed].
+func HashWithSecrets(data []byte, seed uint64, secrets *Secrets) uint64 {
+	return hashWithSecrets(data, seed, secrets, variantDefault)
+}
+
+// Hash computes a 64-bit rapidhash of data with the default seed (0).
+func (s *Secrets) Hash(data []byte) uint64 {
+	return hashWithSecrets(data, 0, s, variantDefault)
+}
+
+// HashWithSeed computes a 64-bit rapidhash of data with the given seed.
+func (s *Secrets) HashWithSeed(data []byte, seed uint64) uint64 {
+	return hashWithSecrets(data, seed, s, variantDefault)
+}
+
+// HashMicro computes a hash of data using the Micro variant with the default
+// seed (0).
+func (s *Secrets) HashMicro(data []byte) uint64 {
+	return hashWithSecrets(data, 0, s, variantMicro)
+}
+
+// HashMicroWithSeed computes a hash of data using the Micro variant with the
+// given seed.
+func (s *Secrets) HashMicroWithSeed(data []byte, seed uint64) uint64 {
+	return hashWithSecrets(data, seed, s, variantMicro)
+}
+
+// HashNano computes a hash of data using the Nano variant with the default
+// seed (0).
+func (s *Secrets) HashNano(data []byte) uint64 {
+	return hashWithSecrets(data, 0, s, variantNano)
+}
+
+// HashNanoWithSeed computes a hash of data using the Nano variant with the
+// given seed.
+func (s *Secrets) HashNanoWithSeed(data []byte, seed uint64) uint64 {
+	return hashWithSecrets(data, seed, s, variantNano)
+}
+
+// New creates a new Hasher using these secrets and the default seed (0).
+func (s *Secrets) New() *Hasher {
+	return newHasher(variantDefault, 0, s)
+}
+
+// NewWithSeed creates a new Hasher using these secrets and the given seed.
+func (s *Secrets) NewWithSeed(seed uint64) *Hasher {
+	return newHasher(variantDefault, seed, s)
+}
+
+// NewMicro creates a new Micro variant Hasher using these secrets and the
+// default seed (0).
+func (s *Secrets) NewMicro() *Hasher {
+	return newHasher(variantMicro, 0, s)
+}
+
+// NewMicroWithSeed creates a new Micro variant Hasher using these secrets and
+// the given seed.
+func (s *Secrets) NewMicroWithSeed(seed uint64) *Hasher {
+	return newHasher(variantMicro, seed, s)
+}
+
+// NewNano creates a new Nano variant Hasher using these secrets and the
+// default seed (0).
+func (s *Secrets) NewNano() *Hasher {
+	return newHasher(variantNano, 0, s)
+}
+
+// NewNanoWithSeed creates a new Nano variant Hasher using these secrets and
+// the given seed.
+func (s *Secrets) NewNanoWithSeed(seed uint64) *Hasher {
+	return newHasher(variantNano, seed, s)
+}
+
+// hashWithSecrets implements every variant with caller-provided secrets.
+// It mirrors the constant-secret paths in rapidhash.go, trading their
+// immediate operands for loads from s.
+func hashWithSecrets(data []byte, seed uint64, s *Secrets, v variant) uint64 {
+	length := len(data)
+	seed ^= mix(seed^s[2], s[1])
+
+	if length <= 16 {
+		var a, b uint64
+		if length >= 4 {
+			p := unsafe.Pointer(unsafe.SliceData(data))
+			if length >= 8 {
+				a = u64(p)
+				b = u64(add(p, uintptr(length-8)))
+			} else {
+				a = u32(p)
+				b = u32(add(p, uintptr(length-4)))
+			}
+			seed ^= uint64(length)
+		} else if length > 0 {
+			a, b = loadUpTo3(data)
+		}
+
+		a ^= s[1]
+		b ^= seed
+		a, b = mum(a, b)
+
+		return mix(a^s[7], b^s[1]^uint64(length))
+	}
+
+	p, i, even, odd := accumWithSecrets(unsafe.Pointer(unsafe.SliceData(data)), length, seed, s, v)
+	seed = even ^ odd
+
+	// The remainder never exceeds the variant's block size, so the steps
+	// past it are skipped naturally.
+	seed = hashTailWithSecrets(p, i, seed, s)
+
+	origP := unsafe.Pointer(unsafe.SliceData(data))
+	a := u64(add(origP, uintptr(length-16))) ^ uint64(i)
+	b := u64(add(origP, uintptr(length-8)))
+
+	a ^= s[1]
+	b ^= seed
+	a, b = mum(a, b)
+
+	return mix(a^s[7], b^s[1]^uint64(i))
+}
+
+// accumWithSecrets runs the block loop of variant v over all but the last
+// 1..BlockSize bytes at p. It returns the position and length of the
+// remainder, and the lanes folded into the XOR of the even and of the odd
+// lanes. Without full blocks, even is seed and odd is 0.
+func accumWithSecrets(p unsafe.Pointer, i int, seed uint64, s *Secrets, v variant) (unsafe.Pointer, int, uint64, uint64) {
+	switch v {
+	case variantDefault:
+		if i > 112 {
+			see1, see2 := seed, seed
+			see3, see4 := seed, seed
+			see5, see6 := seed, seed
+
+			p, i, seed, see1, see2, see3, see4, see5, see6 = accumBlocks(p, i, s, seed, see1, see2, see3, see4, see5, see6)
+
+			return p, i, seed ^ see2 ^ see4 ^ see6, see1 ^ see3 ^ see5
+		}
+	case variantMicro:
+		if i > 80 {
+			see1, see2 := seed, seed
+			see3, see4 := seed, seed
+
+			p, i, seed, see1, see2, see3, see4 = accumBlocksMicro(p, i, s, seed, see1, see2, see3, see4)
+
+			return p, i, seed ^ see2 ^ see4, see1 ^ see3
+		}
+	case variantNano:
+		if i > 48 {
+			see1, see2 := seed, seed
+
+			p, i, seed, see1, see2 = accumBlocksNano(p, i, s, seed, see1, see2)
+
+			return p, i, seed ^ see2, see1
+		}
+	}
+
+	return p, i, seed, 0
+}
+
+// hashTailWithSecrets mixes the 16-byte chunks of the final 1-112 bytes
+// starting at p into seed.
+func hashTailWithSecrets(p unsafe.Pointer, i int, seed uint64, s *Secrets) uint64 {
+	if i > 16 {
+		seed = mix(u64(p)^s[2], u64(add(p, 8))^seed)
+		if i > 32 {
+			seed = mix(u64(add(p, 16))^s[2], u64(add(p, 24))^seed)
+		}
+		if i > 48 {
+			seed = mix(u64(add(p, 32))^s[1], u64(add(p, 40))^seed)
+		}
+		if i > 64 {
+			seed = mix(u64(add(p, 48))^s[1], u64(add(p, 56))^seed)
+		}
+		if i > 80 {
+			seed = mix(u64(add(p, 64))^s[2], u64(add(p, 72))^seed)
+		}
+		if i > 96 {
+			seed = mix(u64(add(p, 80))^s[1], u64(add(p, 88))^seed)
+		}
+	}
+
+	return seed
+}
@@ -0,0 +1,244 @@
+package rapidhash_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+// defaultSecretsTest mirrors the built-in secrets of the C reference.
+var defaultSecretsTest = rapidhash.Secrets{
+	0x2d358dccaa6c78a5, 0x8bb84b93962eacc9, 0x4b33a62ed433d4a3, 0x4d5a2da51de1aa47,
+	0xa0761d6478bd642f, 0xe7037ed1a0b428db, 0x90ed1765281c388c, 0xaaaaaaaaaaaaaaaa,
+}
+
+// secretsVector is {size, Hash, HashMicro, HashNano} for seed 0x1234.
+type secretsVector struct {
+	size      int
+	hash      uint64
+	hashMicro uint64
+	hashNano  uint64
+}
+
+// Test vectors generated from the C reference internal functions, with
+// secrets from make_secret extended to 8 secrets.
+var secretsTestVectors = []struct {
+	secretSeed uint64
+	secrets    rapidhash.Secrets
+	vectors    []secretsVector
+}{
+	{
+		secretSeed: 0,
+		secrets: rapidhash.Secrets{
+			0x27a3335971591ea9, 0xa32e69a6552db495, 0x661eb29ce835a359, 0xb29a710f8b564d93,
+			0xc51b179396c57471, 0x69e8f065c69c2e71, 0x558e668e669ad2a3, 0x1b0fa5c51bd2a64d,
+		},
+		vectors: []secretsVector{
+			{0, 0x9ec03d3149ddfd97, 0x9ec03d3149ddfd97, 0x9ec03d3149ddfd97},
+			{1, 0x438297dc9fe936ac, 0x438297dc9fe936ac, 0x438297dc9fe936ac},
+			{3, 0xa5d00904e9ffbf7a, 0xa5d00904e9ffbf7a, 0xa5d00904e9ffbf7a},
+			{4, 0xba1dd67fe88dbca3, 0xba1dd67fe88dbca3, 0xba1dd67fe88dbca3},
+			{8, 0x26b1ff645a8a6879, 0x26b1ff645a8a6879, 0x26b1ff645a8a6879},
+			{16, 0xb15b4da393dda147, 0xb15b4da393dda147, 0xb15b4da393dda147},
+			{17, 0x9db322c4b19baba4, 0x9db322c4b19baba4, 0x9db322c4b19baba4},
+			{33, 0x79091fd834ee17a9, 0x79091fd834ee17a9, 0x79091fd834ee17a9},
+			{48, 0x36111157bd09e26e, 0x36111157bd09e26e, 0x36111157bd09e26e},
+			{49, 0x50da5db5c6fdd8a5, 0x50da5db5c6fdd8a5, 0x1cc49c592cb47c81},
+			{80, 0xb0275e2411cd6e7c, 0xb0275e2411cd6e7c, 0xfa9d7e091936c777},
+			{81, 0xf6747822b9bb206c, 0xc20a844dcb9f3025, 0x54b24307aaa1c571},
+			{112, 0x8f50f717b26c6648, 0x6b12b9d11829f6ad, 0x1159038bf1a1eeb7},
+			{113, 0x44ba87f90d430df8, 0x1f7f7aa9d87106a7, 0xb6b513183d838b23},
+			{200, 0xa7c39e922ca9fd15, 0xb1dd379b0acd15fe, 0x842611344db2d9f},
+			{448, 0x6785515ee491b891, 0xdcc93e54917f4013, 0x22d2a6c2cdb9fd47},
+			{449, 0x1e99006e5197aa03, 0x7cfceff9e9811e37, 0x68b6dc9826ad6a28},
+			{1000, 0xe4aadc33c650ab5b, 0x3affa8e7e4fd4b00, 0xa963605190692d3f},
+		},
+	},
+	{
+		secretSeed: 42,
+		secrets: rapidhash.Secrets{
+			0xb8599a3659669993, 0x96995c8b53c5aa65, 0x8d1e477463e172d1, 0x875a8d4ecc5aa6a3,
+			0xd8c66cb4cc69b82d, 0xc6740fc63935cc1d, 0xf02e9a87e299d287, 0x561b631d783c330f,
+		},
+		vectors: []secretsVector{
+			{0, 0x55cd2bb1b9bc27ce, 0x55cd2bb1b9bc27ce, 0x55cd2bb1b9bc27ce},
+			{1, 0x448bd6da0a3ccbf5, 0x448bd6da0a3ccbf5, 0x448bd6da0a3ccbf5},
+			{3, 0xf965d694a55ffcdd, 0xf965d694a55ffcdd, 0xf965d694a55ffcdd},
+			{4, 0x134202d330f97934, 0x134202d330f97934, 0x134202d330f97934},
+			{8, 0x5c81250394c2c381, 0x5c81250394c2c381, 0x5c81250394c2c381},
+			{16, 0xd307a6ac661a38c2, 0xd307a6ac661a38c2, 0xd307a6ac661a38c2},
+			{17, 0x458f776712629949, 0x458f776712629949, 0x458f776712629949},
+			{33, 0x15b53378540c2f38, 0x15b53378540c2f38, 0x15b53378540c2f38},
+			{48, 0x20f05f470a0249d4, 0x20f05f470a0249d4, 0x20f05f470a0249d4},
+			{49, 0xa61dbe987ddde607, 0xa61dbe987ddde607, 0xdbd5372700fffb0b},
+			{80, 0x64e8a8c9a512b2ac, 0x64e8a8c9a512b2ac, 0x42875a8b3b246d85},
+			{81, 0x9605a3332472d30a, 0x5817ba92b2659057, 0x209084d3d4803ff3},
+			{112, 0xc35c9ca867dafebd, 0x3ef1e44a49b64856, 0x1c2f950cd995ea9f},
+			{113, 0x140f438b53c6e172, 0x815730f375ba24e5, 0x54b2a185aa36674},
+			{200, 0xc8836460a3db0254, 0x39984b3e15f54953, 0xd0aba468fb32fb1d},
+			{448, 0x90a17e1248e4e832, 0xbca76ef496875e96, 0x3562ba8381bf254f},
+			{449, 0xbb65880c0563ae52, 0x370ec7e5415329d8, 0x474717425ca63fb},
+			{1000, 0x7c0f7e3753249590, 0x3807a7d086a7744e, 0x5b4e500fe1b5d0bd},
+		},
+	},
+}
+
+func TestMakeSecretsAgainstCReference(t *testing.T) {
+	for _, tc := range secretsTestVectors {
+		if got := rapidhash.MakeSecrets(tc.secretSeed); got != tc.secrets {
+			t.Errorf("MakeSecrets(%d) = %#x, want %#x", tc.secretSeed, got, tc.secrets)
+		}
+	}
+}
+
+func TestMakeSecretsWellFormed(t *testing.T) {
+	for seed := uint64(100); seed < 110; seed++ {
+		s := rapidhash.MakeSecrets(seed)
+		for i, secret := range s {
+			if secret&1 == 0 {
+				t.Errorf("seed=%d: secret %d (0x%x) is even", seed, i, secret)
+			}
+			for j := 0; j < 64; j += 8 {
+				if n := bits.OnesCount8(uint8(secret >> j)); n != 4 {
+					t.Errorf("seed=%d: secret %d byte %d has %d bits set, want 4", seed, i, j/8, n)
+				}
+			}
+			for j := 0; j < i; j++ {
+				if n := bits.OnesCount64(s[j] ^ secret); n != 32 {
+					t.Errorf("seed=%d: secrets %d and %d differ in %d bits, want 32", seed, j, i, n)
+				}
+			}
+		}
+	}
+}
+
+func TestSecretsAgainstCReference(t *testing.T) {
+	const seed = 0x1234
+
+	for _, tc := range secretsTestVectors {
+		s := tc.secrets
+		for _, v := range tc.vectors {
+			data := make([]byte, v.size)
+			for i := range data {
+				data[i] = byte(i % 256)
+			}
+
+			if got := rapidhash.HashWithSecrets(data, seed, &s); got != v.hash {
+				t.Errorf("secrets %d size=%d: HashWithSecrets() = 0x%x, want 0x%x", tc.secretSeed, v.size, got, v.hash)
+			}
+			if got := s.HashWithSeed(data, seed); got != v.hash {
+				t.Errorf("secrets %d size=%d: HashWithSeed() = 0x%x, want 0x%x", tc.secretSeed, v.size, got, v.hash)
+			}
+			if got := s.HashMicroWithSeed(data, seed); got != v.hashMicro {
+				t.Errorf("secrets %d size=%d: HashMicroWithSeed() = 0x%x, want 0x%x", tc.secretSeed, v.size, got, v.hashMicro)
+			}
+			if got := s.HashNanoWithSeed(data, seed); got != v.hashNano {
+				t.Errorf("secrets %d size=%d: HashNanoWithSeed() = 0x%x, want 0x%x", tc.secretSeed, v.size, got, v.hashNano)
+			}
+
+			hashers := []struct {
+				name string
+				h    *rapidhash.Hasher
+				want uint64
+			}{
+				{"NewWithSeed", s.NewWithSeed(seed), v.hash},
+				{"NewMicroWithSeed", s.NewMicroWithSeed(seed), v.hashMicro},
+				{"NewNanoWithSeed", s.NewNanoWithSeed(seed), v.hashNano},
+			}
+			for _, hs := range hashers {
+				for i := 0; i < len(data); i += 29 {
+					end := i + 29
+					if end > len(data) {
+						end = len(data)
+					}
+					_, _ = hs.h.Write(data[i:end])
+				}
+				if got := hs.h.Sum64(); got != hs.want {
+					t.Errorf("secrets %d size=%d: %s().Sum64() = 0x%x, want 0x%x", tc.secretSeed, v.size, hs.name, got, hs.want)
+				}
+			}
+		}
+	}
+}
+
+func TestDefaultSecretsMatchHash(t *testing.T) {
+	s := defaultSecretsTest
+
+	for _, size := range []int{0, 1, 3, 4, 8, 16, 17, 48, 49, 80, 81, 112, 113, 448, 449, 1000} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*3 + 1)
+		}
+
+		if got, want := s.Hash(data), rapidhash.Hash(data); got != want {
+			t.Errorf("size=%d: Secrets.Hash() = 0x%x, want 0x%x", size, got, want)
+		}
+		if got, want := s.HashMicro(data), rapidhash.HashMicro(data); got != want {
+			t.Errorf("size=%d: Secrets.HashMicro() = 0x%x, want 0x%x", size, got, want)
+		}
+		if got, want := s.HashNano(data), rapidhash.HashNano(data); got != want {
+			t.Errorf("size=%d: Secrets.HashNano() = 0x%x, want 0x%x", size, got, want)
+		}
+		if got, want := rapidhash.HashWithSecrets(data, 7, &s), rapidhash.HashWithSeed(data, 7); got != want {
+			t.Errorf("size=%d: HashWithSecrets() = 0x%x, want 0x%x", size, got, want)
+		}
+	}
+}
+
+func TestSecretsHasherMarshalBinary(t *testing.T) {
+	s := rapidhash.MakeSecrets(42)
+	data := make([]byte, 700)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	h := s.NewMicro()
+	_, _ = h.Write(data[:333])
+	state, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	for i, secret := range s {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], secret)
+		if bytes.Contains(state, b[:]) {
+			t.Errorf("MarshalBinary() contains secret %d", i)
+		}
+	}
+
+	// The variant comes from the state, the secrets from the receiver.
+	resumed := s.New()
+	if err := resumed.UnmarshalBinary(state); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	_, _ = resumed.Write(data[333:])
+
+	if got, want := resumed.Sum64(), s.HashMicro(data); got != want {
+		t.Errorf("resumed Sum64() = 0x%x, want 0x%x", got, want)
+	}
+
+	other := rapidhash.MakeSecrets(43)
+	for name, h := range map[string]*rapidhash.Hasher{
+		"New":   rapidhash.New(),
+		"zero":  new(rapidhash.Hasher),
+		"other": other.NewMicro(),
+	} {
+		if err := h.UnmarshalBinary(state); err == nil {
+			t.Errorf("%s: UnmarshalBinary of a state with other secrets succeeded", name)
+		}
+	}
+
+	// States of default hashers restore into any hasher with the default
+	// secrets, and only those.
+	state, _ = rapidhash.NewMicro().MarshalBinary()
+	if err := new(rapidhash.Hasher).UnmarshalBinary(state); err != nil {
+		t.Errorf("zero Hasher: UnmarshalBinary: %v", err)
+	}
+	if err := s.New().UnmarshalBinary(state); err == nil {
+		t.Errorf("custom secrets: UnmarshalBinary of a default state succeeded")
+	}
+}
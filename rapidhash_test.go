@@ -291,6 +291,29 @@ func TestAllVariantsAgainstCReference(t *testing.T) {
 		if gotNano != tc.hashNano {
 			t.Errorf("size=%d: HashNano() = 0x%x, want 0x%x", tc.size, gotNano, tc.hashNano)
 		}
+
+		// The streaming hashers must agree when fed in uneven chunks.
+		hashers := []struct {
+			name string
+			h    *rapidhash.Hasher
+			want uint64
+		}{
+			{"New", rapidhash.New(), tc.hash},
+			{"NewMicro", rapidhash.NewMicro(), tc.hashMicro},
+			{"NewNano", rapidhash.NewNano(), tc.hashNano},
+		}
+		for _, hs := range hashers {
+			for i := 0; i < len(data); i += 37 {
+				end := i + 37
+				if end > len(data) {
+					end = len(data)
+				}
+				_, _ = hs.h.Write(data[i:end])
+			}
+			if got := hs.h.Sum64(); got != hs.want {
+				t.Errorf("size=%d: %s().Sum64() = 0x%x, want 0x%x", tc.size, hs.name, got, hs.want)
+			}
+		}
 	}
 }
 
@@ -0,0 +1,116 @@
+package rapidhash
+
+import (
+	"encoding/binary"
+	"runtime"
+	"sync"
+)
+
+const (
+	// TreeLeafSize is the size of the leaves hashed independently by
+	// [HashParallel]. It is part of the tree output definition.
+	TreeLeafSize = 1 << 20
+
+	// TreeVersion identifies the tree output definition of [HashParallel].
+	// It is mixed into every root, and any change to the tree layout gets a
+	// new version.
+	TreeVersion = 1
+)
+
+// HashParallel computes a tree-mode hash of data using the default seed (0)
+// and up to workers goroutines. If workers is less than 1, GOMAXPROCS is
+// used.
+//
+// The result only depends on data, never on workers, but it is a different
+// function from [Hash]: see [HashParallelWithSeed] for the tree definition.
+func HashParallel(data []byte, workers int) uint64 {
+	return HashParallelWithSeed(data, 0, workers)
+}
+
+// HashParallelWithSeed computes a tree-mode hash of data using the provided
+// seed and up to workers goroutines. If workers is less than 1, GOMAXPROCS
+// is used.
+//
+// The input is split into [TreeLeafSize] leaves (the last one may be shorter,
+// and empty input is a single empty leaf), each hashed with [HashWithSeed]
+// and seed. Adjacent digests are then paired left to right, level by level,
+// hashing their 16 little-endian bytes with seed^secret3; an odd digest at
+// the end of a level moves up unchanged. The root is the hash of the final
+// digest, the input length and [TreeVersion], as 24 little-endian bytes,
+// with seed^secret4.
+func HashParallelWithSeed(data []byte, seed uint64, workers int) uint64 {
+	leaves := (len(data) + TreeLeafSize - 1) / TreeLeafSize
+	if leaves == 0 {
+		leaves = 1
+	}
+
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > leaves {
+		workers = leaves
+	}
+
+	digests := make([]uint64, leaves)
+	if workers == 1 {
+		hashLeaves(data, seed, digests, 0, leaves)
+	} else {
+		var wg sync.WaitGroup
+		per := (leaves + workers - 1) / workers
+		for lo := 0; lo < leaves; lo += per {
+			hi := lo + per
+			if hi > leaves {
+				hi = leaves
+			}
+			wg.Add(1)
+			go func(lo, hi int) {
+				defer wg.Done()
+				hashLeaves(data, seed, digests, lo, hi)
+			}(lo, hi)
+		}
+		wg.Wait()
+	}
+
+	var buf [24]byte
+	nodeSeed := seed ^ secret3
+	for n := leaves; n > 1; n = (n + 1) / 2 {
+		for j := 0; j < n/2; j++ {
+			binary.LittleEndian.PutUint64(buf[0:], digests[2*j])
+			binary.LittleEndian.PutUint64(buf[8:], digests[2*j+1])
+			digests[j] = HashWithSeed(buf[:16], nodeSeed)
+		}
+		if n%2 == 1 {
+			digests[n/2] = digests[n-1]
+		}
+	}
+
+	binary.LittleEndian.PutUint64(buf[0:], digests[0])
+	binary.LittleEndian.PutUint64(buf[8:], uint64(len(data)))
+	binary.LittleEndian.PutUint64(buf[16:], TreeVersion)
+
+	return HashWithSeed(buf[:], seed^secret4)
+}
+
+// HashStringParallel computes a tree-mode hash of s using the default seed
+// (0) and up to workers goroutines.
+func HashStringParallel(s string, workers int) uint64 {
+	return HashParallelWithSeed(stringToBytes(s), 0, workers)
+}
+
+// HashStringParallelWithSeed computes a tree-mode hash of s using the
+// provided seed and up to workers goroutines.
+func HashStringParallelWithSeed(s string, seed uint64, workers int) uint64 {
+	return HashParallelWithSeed(stringToBytes(s), seed, workers)
+}
+
+// hashLeaves stores the digests of leaves [lo, hi) of data.
+func hashLeaves(data []byte, seed uint64, digests []uint64, lo, hi int) {
+	for j := lo; j < hi; j++ {
+		start := j * TreeLeafSize
+		end := start + TreeLeafSize
+		if end > len(data) {
+			end = len(data)
+		}
+		digests[j] = HashWithSeed(data[start:end], seed)
+	}
+}
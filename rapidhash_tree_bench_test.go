@@ -0,0 +1,29 @@
+package rapidhash_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+func BenchmarkHashParallel(b *testing.B) {
+	data := make([]byte, 64*rapidhash.TreeLeafSize)
+
+	b.Run("Hash", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			rapidhash.Hash(data)
+		}
+	})
+
+	for _, workers := range []int{1, 4, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				rapidhash.HashParallel(data, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package rapidhash_test
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"go.dw1.io/rapidhash"
+)
+
+// treeReference follows the documented tree definition sequentially.
+func treeReference(data []byte, seed uint64) uint64 {
+	var level []uint64
+	for start := 0; start < len(data) || start == 0; start += rapidhash.TreeLeafSize {
+		end := start + rapidhash.TreeLeafSize
+		if end > len(data) {
+			end = len(data)
+		}
+		level = append(level, rapidhash.HashWithSeed(data[start:end], seed))
+	}
+
+	const secret3, secret4 = 0x4d5a2da51de1aa47, 0xa0761d6478bd642f
+	for len(level) > 1 {
+		var next []uint64
+		for j := 0; j+1 < len(level); j += 2 {
+			buf := binary.LittleEndian.AppendUint64(nil, level[j])
+			buf = binary.LittleEndian.AppendUint64(buf, level[j+1])
+			next = append(next, rapidhash.HashWithSeed(buf, seed^secret3))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+
+	buf := binary.LittleEndian.AppendUint64(nil, level[0])
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(data)))
+	buf = binary.LittleEndian.AppendUint64(buf, rapidhash.TreeVersion)
+
+	return rapidhash.HashWithSeed(buf, seed^secret4)
+}
+
+func TestHashParallelWorkerIndependent(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	data := make([]byte, 5*rapidhash.TreeLeafSize+777)
+	rng.Read(data)
+
+	sizes := []int{0, 1, 100, rapidhash.TreeLeafSize - 1, rapidhash.TreeLeafSize, rapidhash.TreeLeafSize + 1,
+		2 * rapidhash.TreeLeafSize, 3*rapidhash.TreeLeafSize + 17, len(data)}
+
+	for _, size := range sizes {
+		for _, seed := range []uint64{0, 42} {
+			want := treeReference(data[:size], seed)
+			for _, workers := range []int{0, 1, 2, 3, 4, 7, 64} {
+				if got := rapidhash.HashParallelWithSeed(data[:size], seed, workers); got != want {
+					t.Errorf("size=%d seed=%d workers=%d: got 0x%x, want 0x%x", size, seed, workers, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestHashParallelKnownValues(t *testing.T) {
+	data := make([]byte, 2*rapidhash.TreeLeafSize+5)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	tests := []struct {
+		size int
+		want uint64
+	}{
+		{0, 0x7c7e2bb90c2682ce},
+		{11, 0x85647eb97ee2c091},
+		{len(data), 0x784582097498da02},
+	}
+
+	for _, tt := range tests {
+		if got := rapidhash.HashParallel(data[:tt.size], 2); got != tt.want {
+			t.Errorf("HashParallel(%d bytes) = 0x%x, want 0x%x", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestHashParallelDistinguishesInputs(t *testing.T) {
+	a := make([]byte, rapidhash.TreeLeafSize)
+	b := make([]byte, rapidhash.TreeLeafSize+1)
+
+	if rapidhash.HashParallel(a, 1) == rapidhash.HashParallel(b, 1) {
+		t.Error("trailing zero byte did not change the tree hash")
+	}
+	if rapidhash.HashParallel(a, 1) == rapidhash.HashParallelWithSeed(a, 1, 1) {
+		t.Error("seed did not change the tree hash")
+	}
+	if got, want := rapidhash.HashStringParallel("hello", 4), rapidhash.HashParallel([]byte("hello"), 1); got != want {
+		t.Errorf("HashStringParallel = 0x%x, want 0x%x", got, want)
+	}
+}
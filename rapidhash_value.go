@@ -0,0 +1,236 @@
+package rapidhash
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// ErrCycle is returned by [HashValue] for a value that refers to itself.
+var ErrCycle = errors.New("rapidhash: value contains a cycle")
+
+// ValueOptions configures [HashValue]. The zero value uses seed 0.
+type ValueOptions struct {
+	// Seed is the seed of the hash.
+	Seed uint64
+
+	// NilEqualsEmpty makes nil slices and maps hash the same as empty ones.
+	NilEqualsEmpty bool
+}
+
+// HashValue returns a deep hash of v, which need not be comparable, using
+// the stable encoding of [HashComparableStable] with these changes:
+//   - a pointer is followed and encoded as a marker byte, 0 for nil and 1
+//     otherwise, then the value it points to;
+//   - a slice is encoded as a marker byte, 0 for nil, then its length as a
+//     uvarint and its elements;
+//   - a map is encoded as a marker byte, 0 for nil, then its length as a
+//     uvarint and the sum of the hashes of the encodings of its entries, so
+//     the result does not depend on iteration order.
+//
+// Values that are deeply equal, as by [reflect.DeepEqual], hash the same,
+// except that NaNs are equal to each other and [Hashable] types and struct
+// tags are honored as in the comparable functions; a field tagged ignorezero
+// is skipped while it is a nil pointer or an empty slice or map. Channels,
+// functions and unsafe pointers return an [*UnsupportedTypeError], and
+// pointers, slices or maps that lead back to a value being hashed return
+// [ErrCycle].
+func HashValue(v any, opts *ValueOptions) (uint64, error) {
+	var o ValueOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	e := encState{seed: o.Seed, stable: true, deep: &deepState{nilEmpty: o.NilEqualsEmpty}}
+	e.any(v)
+	if e.err != nil {
+		if e.pooled {
+			hasherPool.Put(e.h)
+		}
+		return 0, e.err
+	}
+
+	return e.sum(), nil
+}
+
+// deepEncoders caches the *typeEncoder of every type seen by HashValue.
+var deepEncoders sync.Map // map[reflect.Type]*typeEncoder
+
+// deepEncoderOf returns the cached HashValue encoder of t.
+func deepEncoderOf(t reflect.Type) *typeEncoder {
+	if enc, ok := deepEncoders.Load(t); ok {
+		return enc.(*typeEncoder)
+	}
+	enc, _ := deepEncoders.LoadOrStore(t, newTypeEncoder(t, true))
+
+	return enc.(*typeEncoder)
+}
+
+// compileDeep appends the ops of a pointer, slice or map for HashValue.
+// Their element types are only compiled when they are reached, which
+// allows recursive types.
+func (enc *typeEncoder) compileDeep(ops []encOp, t reflect.Type, off uintptr) []encOp {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return append(ops, encOp{code: opPointer, off: off, typ: t.Elem()})
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && hashableFunc(t.Elem()) == nil {
+			return append(ops, encOp{code: opByteSlice, off: off})
+		}
+		return append(ops, encOp{code: opSlice, off: off, typ: t.Elem()})
+	case reflect.Map:
+		return append(ops, encOp{code: opMap, off: off, typ: t})
+	}
+
+	if enc.bad == nil {
+		enc.bad = t
+	}
+
+	return ops
+}
+
+// deepState is the state HashValue shares across an encoding.
+type deepState struct {
+	nilEmpty bool
+	path     []visit // the pointers, slices and maps being encoded
+}
+
+// visit identifies a pointer, slice or map by its code, the address it
+// refers to and its element type, as with [reflect.DeepEqual].
+type visit struct {
+	code opCode
+	p    unsafe.Pointer
+	t    reflect.Type
+}
+
+// enter adds v to the path, or reports false if it is already on it.
+func (d *deepState) enter(v visit) bool {
+	for _, w := range d.path {
+		if w == v {
+			return false
+		}
+	}
+	d.path = append(d.path, v)
+
+	return true
+}
+
+func (d *deepState) leave() {
+	d.path = d.path[:len(d.path)-1]
+}
+
+// runDeep runs an op that only HashValue encoders have. The element types
+// of pointers, slices and maps are checked even if there are no elements, so
+// whether a value can be hashed only depends on its type.
+func (e *encState) runDeep(op *encOp, q unsafe.Pointer) {
+	switch op.code {
+	case opPointer:
+		enc := deepEncoderOf(op.typ)
+		if enc.bad != nil {
+			e.fail(enc.bad)
+			return
+		}
+		p := *(*unsafe.Pointer)(q)
+		e.reserve(1)
+		if p == nil {
+			e.put8(0)
+			return
+		}
+		e.put8(1)
+		e.follow(visit{opPointer, p, op.typ}, func() {
+			e.run(enc.ops, p)
+		})
+	case opByteSlice:
+		b := *(*[]byte)(q)
+		if e.length(b == nil, len(b)) {
+			e.write(b)
+		}
+	case opSlice:
+		enc := deepEncoderOf(op.typ)
+		if enc.bad != nil {
+			e.fail(enc.bad)
+			return
+		}
+		b := *(*[]byte)(q) // only the header is read
+		if !e.length(b == nil, len(b)) || len(b) == 0 {
+			return
+		}
+		p, size := unsafe.Pointer(unsafe.SliceData(b)), op.typ.Size()
+		e.follow(visit{opSlice, p, op.typ}, func() {
+			for i := 0; i < len(b) && e.err == nil; i++ {
+				e.run(enc.ops, add(p, uintptr(i)*size))
+			}
+		})
+	case opMap:
+		kenc, venc := deepEncoderOf(op.typ.Key()), deepEncoderOf(op.typ.Elem())
+		for _, enc := range [...]*typeEncoder{kenc, venc} {
+			if enc.bad != nil {
+				e.fail(enc.bad)
+				return
+			}
+		}
+		m := reflect.NewAt(op.typ, q).Elem()
+		if !e.length(m.IsNil(), m.Len()) {
+			return
+		}
+		e.follow(visit{opMap, m.UnsafePointer(), op.typ}, func() {
+			e.mapEntries(m, kenc, venc)
+		})
+	}
+}
+
+// length writes the marker byte and length of a slice or map and reports
+// whether it was not nil, or nil but written as empty.
+func (e *encState) length(isNil bool, n int) bool {
+	e.reserve(1 + binary.MaxVarintLen64)
+	if isNil && !e.deep.nilEmpty {
+		e.put8(0)
+		return false
+	}
+	e.put8(1)
+	e.n += binary.PutUvarint(e.buf[e.n:], uint64(n))
+
+	return true
+}
+
+// follow calls encode with v on the path, or fails with ErrCycle if v is
+// already being encoded. What encode writes is inside the current value.
+func (e *encState) follow(v visit, encode func()) {
+	if !e.deep.enter(v) {
+		if e.err == nil {
+			e.err = ErrCycle
+		}
+		return
+	}
+	nested := e.nested
+	e.nested = true
+	encode()
+	e.nested = nested
+	e.deep.leave()
+}
+
+// mapEntries writes the sum of the hashes of the entries of m, whose keys
+// and values kenc and venc encode. The entries are hashed with the seed of e,
+// so that which maps have equal sums depends on the seed too.
+func (e *encState) mapEntries(m reflect.Value, kenc, venc *typeEncoder) {
+	k, v := reflect.New(m.Type().Key()).Elem(), reflect.New(m.Type().Elem()).Elem()
+	var sum uint64
+	for it := m.MapRange(); it.Next() && e.err == nil; {
+		k.SetIterKey(it)
+		v.SetIterValue(it)
+
+		entry := encState{seed: e.seed, stable: true, deep: e.deep, nested: true}
+		entry.run(kenc.ops, k.Addr().UnsafePointer())
+		entry.run(venc.ops, v.Addr().UnsafePointer())
+		sum += entry.sum()
+		if entry.err != nil {
+			e.err = entry.err
+		}
+	}
+
+	e.reserve(8)
+	binary.LittleEndian.PutUint64(e.buf[e.n:], sum)
+	e.n += 8
+}
@@ -0,0 +1,270 @@
+package rapidhash_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"go.dw1.io/rapidhash"
+)
+
+type (
+	valueNode struct {
+		Name string
+		Next *valueNode
+	}
+	valueConfig struct {
+		Name     string
+		Tags     []string
+		Limits   map[string]int
+		Backends []*valueBackend
+		Extra    map[string]any
+		Token    string `rapidhash:"-"`
+	}
+	valueBackend struct {
+		Addr    string
+		Weight  float64
+		Timeout *time.Duration
+	}
+)
+
+func hashValue(t *testing.T, v any, opts *rapidhash.ValueOptions) uint64 {
+	t.Helper()
+
+	h, err := rapidhash.HashValue(v, opts)
+	if err != nil {
+		t.Fatalf("HashValue(%#v): %v", v, err)
+	}
+
+	return h
+}
+
+func checkSameValue(t *testing.T, a, b any) {
+	t.Helper()
+
+	if ha, hb := hashValue(t, a, nil), hashValue(t, b, nil); ha != hb {
+		t.Errorf("HashValue(%#v) = 0x%x, HashValue(%#v) = 0x%x", a, ha, b, hb)
+	}
+}
+
+func checkOtherValue(t *testing.T, a, b any) {
+	t.Helper()
+
+	if ha, hb := hashValue(t, a, nil), hashValue(t, b, nil); ha == hb {
+		t.Errorf("HashValue(%#v) == HashValue(%#v) = 0x%x", a, b, ha)
+	}
+}
+
+func TestHashValueComparable(t *testing.T) {
+	// Values without pointers, slices or maps hash as HashComparableStable.
+	for _, v := range []any{nil, 1, "s", fastID{1, 2}, [2]float64{math.NaN(), 1}, tagFold{Name: "A"}} {
+		want, err := rapidhash.HashComparableStableWithSeed(v, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hashValue(t, v, &rapidhash.ValueOptions{Seed: 5}); got != want {
+			t.Errorf("HashValue(%#v) = 0x%x, want 0x%x", v, got, want)
+		}
+	}
+}
+
+func TestHashValueSlices(t *testing.T) {
+	checkSameValue(t, []int{1, 2, 3}, []int{1, 2, 3})
+	checkSameValue(t, []byte("abc"), []byte{'a', 'b', 'c'})
+	checkSameValue(t, [][]string{{"a"}, {"b", "c"}}, [][]string{{"a"}, {"b", "c"}})
+	checkOtherValue(t, []int{1, 2, 3}, []int{3, 2, 1})
+	checkOtherValue(t, [][]string{{"a"}, {"b"}}, [][]string{{"a", "b"}})
+	checkOtherValue(t, []string{"ab"}, []string{"a", "b"})
+	checkOtherValue(t, []byte("ab"), "ab")
+	checkOtherValue(t, []int{}, []int(nil))
+	checkOtherValue(t, []int{}, []uint{})
+}
+
+func TestHashValueMaps(t *testing.T) {
+	a := map[string]int{}
+	b := map[string]int{}
+	for i := 0; i < 100; i++ {
+		a[string(rune('a'+i%26))+string(rune('0'+i/26))] = i
+	}
+	for k, v := range a {
+		b[k] = v
+	}
+	checkSameValue(t, a, b)
+
+	// Iteration order is random, so hashing again must not change anything.
+	h := hashValue(t, a, nil)
+	for i := 0; i < 20; i++ {
+		if got := hashValue(t, a, nil); got != h {
+			t.Fatalf("HashValue(map) = 0x%x, then 0x%x", h, got)
+		}
+	}
+
+	checkOtherValue(t, map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "b": 1})
+	checkOtherValue(t, map[string]int{"a": 1}, map[string]int{"a": 1, "b": 0})
+	checkOtherValue(t, map[string]int{"a": 1, "b": 1}, map[string]int{})
+	checkOtherValue(t, map[string]int{}, map[string]int(nil))
+	checkSameValue(t, map[any][]int{1: {1}, "1": {2}}, map[any][]int{"1": {2}, 1: {1}})
+}
+
+func TestHashValueMapSeed(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+	for _, v := range []any{m, valueConfig{Limits: m}, []map[string]int{m}} {
+		h1 := hashValue(t, v, &rapidhash.ValueOptions{Seed: 1})
+		h2 := hashValue(t, v, &rapidhash.ValueOptions{Seed: 2})
+		if h1 == h2 {
+			t.Errorf("HashValue(%#v) = 0x%x with seeds 1 and 2", v, h1)
+		}
+	}
+}
+
+func TestHashValuePointers(t *testing.T) {
+	x, y := 1, 1
+	checkSameValue(t, &x, &y)
+	checkSameValue(t, &valueNode{Name: "a", Next: &valueNode{Name: "b"}}, &valueNode{Name: "a", Next: &valueNode{Name: "b"}})
+	checkOtherValue(t, &valueNode{Name: "a"}, &valueNode{Name: "a", Next: &valueNode{}})
+	checkOtherValue(t, (*int)(nil), new(int))
+	checkOtherValue(t, &x, x)
+
+	// A pointer reached twice, but not through itself, is not a cycle.
+	shared := &valueNode{Name: "s"}
+	checkSameValue(t, []*valueNode{shared, shared}, []*valueNode{{Name: "s"}, {Name: "s"}})
+}
+
+func TestHashValueCycles(t *testing.T) {
+	n := &valueNode{Name: "a", Next: &valueNode{Name: "b"}}
+	n.Next.Next = n
+
+	s := []any{1, nil}
+	s[1] = s
+
+	m := map[string]any{}
+	m["self"] = m
+
+	var x any
+	x = &x
+
+	for _, v := range []any{n, *n, s, m, &x} {
+		if _, err := rapidhash.HashValue(v, nil); !errors.Is(err, rapidhash.ErrCycle) {
+			t.Errorf("HashValue(%T) error = %v, want ErrCycle", v, err)
+		}
+	}
+}
+
+func TestHashValueUnsupported(t *testing.T) {
+	type withFunc struct {
+		F func()
+	}
+	type withChan struct {
+		C *chan int
+	}
+
+	for _, v := range []any{
+		make(chan int),
+		func() {},
+		[]func(){},
+		map[string]chan int(nil),
+		withFunc{},
+		&withChan{},
+		[]any{1, make(chan int)},
+		map[int]any{1: func() {}},
+	} {
+		_, err := rapidhash.HashValue(v, nil)
+		var ute *rapidhash.UnsupportedTypeError
+		if !errors.As(err, &ute) {
+			t.Errorf("HashValue(%T) error = %v, want *UnsupportedTypeError", v, err)
+		}
+	}
+}
+
+func TestHashValueNilEqualsEmpty(t *testing.T) {
+	opts := &rapidhash.ValueOptions{NilEqualsEmpty: true}
+	pairs := [][2]any{
+		{[]int(nil), []int{}},
+		{[]byte(nil), []byte{}},
+		{map[string]int(nil), map[string]int{}},
+		{valueConfig{}, valueConfig{Tags: []string{}, Limits: map[string]int{}}},
+	}
+	for _, p := range pairs {
+		if a, b := hashValue(t, p[0], opts), hashValue(t, p[1], opts); a != b {
+			t.Errorf("HashValue(%#v) = 0x%x, HashValue(%#v) = 0x%x", p[0], a, p[1], b)
+		}
+	}
+
+	if a, b := hashValue(t, (*int)(nil), opts), hashValue(t, new(int), opts); a == b {
+		t.Errorf("nil and non-nil pointers both hash to 0x%x", a)
+	}
+}
+
+func TestHashValueIgnoreZero(t *testing.T) {
+	type node struct {
+		Name  string
+		Tags  []string       `rapidhash:"ignorezero"`
+		Attrs map[string]int `rapidhash:"ignorezero"`
+		Next  *node          `rapidhash:"ignorezero"`
+	}
+
+	checkSameValue(t, node{Name: "a"}, node{Name: "a", Tags: []string{}, Attrs: map[string]int{}})
+	checkOtherValue(t, node{Name: "a"}, node{Name: "a", Tags: []string{""}})
+	checkOtherValue(t, node{Name: "a"}, node{Name: "a", Attrs: map[string]int{"": 0}})
+	checkOtherValue(t, node{Name: "a"}, node{Name: "a", Next: &node{}})
+	checkOtherValue(t, []node{{Tags: []string{"x"}}, {}}, []node{{}, {Tags: []string{"x"}}})
+}
+
+func TestHashValueConfigTree(t *testing.T) {
+	timeout := 3 * time.Second
+	newConfig := func() valueConfig {
+		d := timeout
+		return valueConfig{
+			Name:   "api",
+			Tags:   []string{"prod", "eu"},
+			Limits: map[string]int{"rps": 100, "burst": 20},
+			Backends: []*valueBackend{
+				{Addr: "10.0.0.1:80", Weight: 0.5, Timeout: &d},
+				{Addr: "10.0.0.2:80", Weight: 0.5},
+			},
+			Extra: map[string]any{"retries": 3, "paths": []any{"/a", "/b"}},
+			Token: "secret",
+		}
+	}
+
+	a, b := newConfig(), newConfig()
+	b.Token = "other"
+	checkSameValue(t, a, b)
+	checkSameValue(t, &a, &b)
+
+	changes := []func(c *valueConfig){
+		func(c *valueConfig) { c.Tags[1] = "us" },
+		func(c *valueConfig) { c.Limits["rps"] = 101 },
+		func(c *valueConfig) { *c.Backends[0].Timeout = time.Second },
+		func(c *valueConfig) { c.Backends[1].Weight = 0.25 },
+		func(c *valueConfig) { c.Extra["paths"].([]any)[0] = "/c" },
+		func(c *valueConfig) { c.Backends = c.Backends[:1] },
+	}
+	for i, change := range changes {
+		c := newConfig()
+		change(&c)
+		if hashValue(t, a, nil) == hashValue(t, c, nil) {
+			t.Errorf("change %d left the hash unchanged", i)
+		}
+	}
+}
+
+func TestHashValueJSON(t *testing.T) {
+	decode := func(s string) any {
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	checkSameValue(t,
+		decode(`{"user":{"id":1,"roles":["a","b"]},"page":2}`),
+		decode(`{"page":2,"user":{"roles":["a","b"],"id":1}}`))
+	checkOtherValue(t,
+		decode(`{"user":{"id":1,"roles":["a","b"]}}`),
+		decode(`{"user":{"id":1,"roles":["b","a"]}}`))
+	checkOtherValue(t, decode(`{"a":null}`), decode(`{}`))
+}